@@ -0,0 +1,55 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try
+
+// attrsError annotates an error with key-value pairs.
+// It does not alter the error message.
+type attrsError struct {
+	err   error
+	attrs []any
+}
+
+func (e *attrsError) Error() string { return e.err.Error() }
+func (e *attrsError) Unwrap() error { return e.err }
+
+// EAttrs panics if err is non-nil, annotating it with attrs,
+// which are alternating keys and values in the style of log/slog.
+// The attributes do not alter the error message and
+// can be retrieved from the error with Attrs.
+//
+//	try.EAttrs(os.Remove(path), "path", path, "attempt", n)
+func EAttrs(err error, attrs ...any) {
+	if err != nil {
+		throw(&attrsError{err, append([]any(nil), attrs...)})
+	}
+}
+
+// Attrs returns the key-value pairs attached to err by EAttrs,
+// including those attached to any errors it wraps.
+// Attributes of outer errors precede those of inner errors.
+func Attrs(err error) []any {
+	var attrs []any
+	var walk func(error)
+	walk = func(err error) {
+		for err != nil {
+			if ae, ok := err.(*attrsError); ok {
+				attrs = append(attrs, ae.attrs...)
+			}
+			switch u := err.(type) {
+			case interface{ Unwrap() error }:
+				err = u.Unwrap()
+			case interface{ Unwrap() []error }:
+				for _, err := range u.Unwrap() {
+					walk(err)
+				}
+				return
+			default:
+				return
+			}
+		}
+	}
+	walk(err)
+	return attrs
+}
@@ -0,0 +1,45 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/dsnet/try"
+)
+
+func TestEAttrs(t *testing.T) {
+	var gotErr error
+	func() {
+		defer try.Handle(&gotErr)
+		try.EAttrs(nil, "never", 0)
+		try.EAttrs(io.EOF, "path", "config.json", "attempt", 2)
+	}()
+	if gotErr == nil || gotErr.Error() != io.EOF.Error() {
+		t.Errorf("recovered error: got %v, want %v", gotErr, io.EOF)
+	}
+	if !errors.Is(gotErr, io.EOF) {
+		t.Errorf("errors.Is(%v, io.EOF) = false, want true", gotErr)
+	}
+
+	var inner error
+	func() {
+		defer try.Handle(&inner)
+		try.EAttrs(io.ErrUnexpectedEOF, "offset", 5)
+	}()
+	joined := errors.Join(fmt.Errorf("wrapped: %w", gotErr), inner)
+	got := try.Attrs(joined)
+	want := []any{"path", "config.json", "attempt", 2, "offset", 5}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Attrs() = %v, want %v", got, want)
+	}
+	if got := try.Attrs(io.EOF); got != nil {
+		t.Errorf("Attrs(io.EOF) = %v, want nil", got)
+	}
+}
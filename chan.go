@@ -0,0 +1,123 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try
+
+import "sync"
+
+// HandleChan recovers an error previously panicked with an E function
+// and sends it on ch. If no panic occurred, it sends nil on ch.
+// The sent error is an *Error so that the receiver can retrieve
+// the frame in which it occurred.
+// Other panics are not recovered and nothing is sent.
+//
+// HandleChan is intended for goroutines that report their result
+// over a channel rather than a return value:
+//
+//	errc := make(chan error, 1)
+//	go func() {
+//		defer try.HandleChan(errc)
+//		...
+//	}()
+//	if err := <-errc; err != nil {
+//		...
+//	}
+func HandleChan(ch chan<- error) {
+	recovered := recover()
+	if recovered == nil {
+		ch <- nil
+		return
+	}
+	r(recovered, func(w *Error) { ch <- w })
+}
+
+// Recv receives a Result from ch and returns its value,
+// reporting false if ch is closed and empty.
+// It panics if the error of the Result is non-nil.
+//
+//	results := make(chan try.Result[[]byte])
+//	go func() {
+//		defer close(results)
+//		for _, path := range paths {
+//			results <- try.T1(os.ReadFile(path))
+//		}
+//	}()
+//	for {
+//		b, ok := try.Recv(results)
+//		if !ok {
+//			break
+//		}
+//		...
+//	}
+func Recv[T any](ch <-chan Result[T]) (T, bool) {
+	r, ok := <-ch
+	if !ok {
+		return r.V1, false
+	}
+	if r.Err != nil {
+		throw(r.Err)
+	}
+	return r.V1, true
+}
+
+// Relay transports the outcome of a goroutine to other goroutines.
+// The goroutine defers a call to Handle, which recovers from any panic
+// as with HandleAny, while other goroutines call Wait or Err for the result.
+// An error panicked by an E function retains the frame in which it occurred.
+// A zero Relay is ready for use and must not be copied after first use.
+//
+//	var relay try.Relay
+//	go func() {
+//		defer relay.Handle()
+//		...
+//	}()
+//	...
+//	if err := relay.Wait(); err != nil {
+//		...
+//	}
+type Relay struct {
+	once sync.Once
+	done chan struct{}
+	err  error
+}
+
+func (rl *Relay) init() {
+	rl.once.Do(func() { rl.done = make(chan struct{}) })
+}
+
+// Handle recovers from any panic and makes it available to Wait and Err as an error.
+// It must be deferred exactly once by the goroutine whose outcome is relayed.
+func (rl *Relay) Handle() {
+	v := recover()
+	rl.init()
+	if v != nil {
+		if w, ok := v.(*Error); ok {
+			defer notify(w)
+		}
+		rl.err = panicError(v)
+	}
+	close(rl.done)
+}
+
+// Done returns a channel that is closed once Handle has run.
+func (rl *Relay) Done() <-chan struct{} {
+	rl.init()
+	return rl.done
+}
+
+// Wait blocks until Handle has run and returns the recovered error, if any.
+func (rl *Relay) Wait() error {
+	<-rl.Done()
+	return rl.err
+}
+
+// Err returns the recovered error if Handle has run, and nil otherwise.
+func (rl *Relay) Err() error {
+	select {
+	case <-rl.Done():
+		return rl.err
+	default:
+		return nil
+	}
+}
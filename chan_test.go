@@ -0,0 +1,101 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/dsnet/try"
+)
+
+func TestHandleChan(t *testing.T) {
+	tests := []struct {
+		in      error
+		wantErr error
+		wantMsg string
+	}{
+		{in: nil, wantErr: nil},
+		{in: io.EOF, wantErr: io.EOF, wantMsg: "x.go:4: try_test.TestHandleChan.func1: EOF"},
+	}
+	for _, tt := range tests {
+		errc := make(chan error, 2)
+		go func() {
+			defer try.HandleChan(errc)
+//line x.go:4
+			try.E(tt.in)
+		}()
+		err := <-errc
+		if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+			t.Errorf("HandleChan(%v) sent %v, want %v", tt.in, err, tt.wantErr)
+		}
+		if err != nil && try.TrimDebug(err.Error()) != tt.wantMsg {
+			t.Errorf("HandleChan(%v) sent %q, want %q", tt.in, err.Error(), tt.wantMsg)
+		}
+		select {
+		case err := <-errc:
+			t.Errorf("HandleChan(%v) sent a second error: %v", tt.in, err)
+		default:
+		}
+	}
+}
+
+func TestRecv(t *testing.T) {
+	ch := make(chan try.Result[int], 3)
+	ch <- try.T1(1, nil)
+	ch <- try.Result[int]{V1: 2}
+	ch <- try.T1(0, io.ErrUnexpectedEOF)
+	close(ch)
+
+	var got []int
+	err := func() (err error) {
+		defer try.Handle(&err)
+		for {
+			v, ok := try.Recv(ch)
+			if !ok {
+				return nil
+			}
+			got = append(got, v)
+		}
+	}()
+	if err != io.ErrUnexpectedEOF || len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Errorf("Recv = (%v, %v), want ([1 2], %v)", got, err, io.ErrUnexpectedEOF)
+	}
+
+	if v, ok := try.Recv(ch); v != 0 || ok {
+		t.Errorf("Recv on closed channel = (%v, %v), want (0, false)", v, ok)
+	}
+}
+
+func TestRelay(t *testing.T) {
+	var relay try.Relay
+	release := make(chan struct{})
+	go func() {
+		defer relay.Handle()
+		<-release
+//line x.go:4
+		try.E(io.EOF)
+	}()
+	if err := relay.Err(); err != nil {
+		t.Errorf("Err() before completion = %v, want nil", err)
+	}
+	close(release)
+	err := relay.Wait()
+	if !errors.Is(err, io.EOF) || try.TrimDebug(err.Error()) != "x.go:4: try_test.TestRelay.func1: EOF" {
+		t.Errorf("Wait() = %q, want x.go:4: try_test.TestRelay.func1: EOF", err)
+	}
+	if relay.Err() != err {
+		t.Errorf("Err() = %v, want %v", relay.Err(), err)
+	}
+
+	var ok try.Relay
+	go func() {
+		defer ok.Handle()
+	}()
+	if err := ok.Wait(); err != nil {
+		t.Errorf("Wait() = %v, want nil", err)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try
+
+import "io"
+
+// HandleClose recovers an error previously panicked with an E function and stores it into errptr.
+// If it recovers an error, it closes each of closers in reverse order,
+// joining any errors from Close with the recovered error as with errors.Join.
+// Nil closers are skipped.
+//
+// Since the arguments to a deferred call are evaluated when the defer
+// statement executes, HandleClose must be deferred after the resources are opened:
+//
+//	func open() (a *A, b *B, err error) {
+//		defer try.Handle(&err)
+//		a = try.E1(openA())
+//		defer try.HandleClose(&err, a)
+//		b = try.E1(openB())
+//		return a, b, nil
+//	}
+func HandleClose(errptr *error, closers ...io.Closer) {
+	r(recover(), func(w *Error) {
+		checkErrptr(errptr, w)
+		err := w.err
+		for i := len(closers) - 1; i >= 0; i-- {
+			if closers[i] != nil {
+				err = join(err, closers[i].Close())
+			}
+		}
+		*errptr = err
+	})
+}
+
+// HandleDeferred recovers an error previously panicked with an E function and stores it into errptr.
+// Regardless of whether it recovers an error, it then calls fn and
+// joins any error it returns with *errptr as with errors.Join.
+//
+//	func save(path string, v any) (err error) {
+//		defer try.Handle(&err)
+//		f := try.E1(os.Create(path))
+//		defer try.HandleDeferred(&err, f.Close)
+//		try.E(json.NewEncoder(f).Encode(v))
+//		return nil
+//	}
+func HandleDeferred(errptr *error, fn func() error) {
+	defer func() {
+		err := fn()
+		if err != nil {
+			checkErrptr(errptr, err)
+		}
+		if errptr != nil {
+			*errptr = join(*errptr, err)
+		}
+	}()
+	r(recover(), func(w *Error) {
+		checkErrptr(errptr, w)
+		*errptr = w.err
+	})
+}
@@ -0,0 +1,90 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"reflect"
+	"testing"
+
+	"github.com/dsnet/try"
+)
+
+type closer struct {
+	name   string
+	err    error
+	closed *[]string
+}
+
+func (c *closer) Close() error {
+	*c.closed = append(*c.closed, c.name)
+	return c.err
+}
+
+func TestHandleClose(t *testing.T) {
+	var closed []string
+	a := &closer{"a", nil, &closed}
+	b := &closer{"b", fs.ErrClosed, &closed}
+
+	err := func() (err error) {
+		defer try.HandleClose(&err, a, nil, b)
+		return nil
+	}()
+	if err != nil || len(closed) > 0 {
+		t.Errorf("HandleClose on success: got (%v, %v), want (nil, [])", err, closed)
+	}
+
+	err = func() (err error) {
+		defer try.HandleClose(&err, a, nil, b)
+		try.E(io.EOF)
+		return nil
+	}()
+	for _, want := range []error{io.EOF, fs.ErrClosed} {
+		if !errors.Is(err, want) {
+			t.Errorf("errors.Is(%v, %v) = false, want true", err, want)
+		}
+	}
+	if want := []string{"b", "a"}; !reflect.DeepEqual(closed, want) {
+		t.Errorf("closed = %v, want %v", closed, want)
+	}
+}
+
+func TestHandleDeferred(t *testing.T) {
+	tests := []struct {
+		name     string
+		runErr   error
+		closeErr error
+		want     []error
+	}{
+		{name: "Success"},
+		{name: "RunFailure", runErr: io.EOF, want: []error{io.EOF}},
+		{name: "CloseFailure", closeErr: fs.ErrClosed, want: []error{fs.ErrClosed}},
+		{name: "BothFailure", runErr: io.EOF, closeErr: fs.ErrClosed, want: []error{io.EOF, fs.ErrClosed}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var closed []string
+			c := &closer{"c", tt.closeErr, &closed}
+			err := func() (err error) {
+				defer try.HandleDeferred(&err, c.Close)
+				try.E(tt.runErr)
+				return nil
+			}()
+			if len(closed) != 1 {
+				t.Errorf("Close called %d times, want 1", len(closed))
+			}
+			if (err != nil) != (len(tt.want) > 0) {
+				t.Errorf("HandleDeferred: got %v, want %v", err, tt.want)
+			}
+			for _, want := range tt.want {
+				if !errors.Is(err, want) {
+					t.Errorf("errors.Is(%v, %v) = false, want true", err, want)
+				}
+			}
+		})
+	}
+}
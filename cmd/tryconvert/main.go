@@ -0,0 +1,433 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+// Command tryconvert rewrites explicit error checks into calls to the E functions
+// of package try.
+//
+// Usage:
+//
+//	tryconvert [flags] [path ...]
+//
+// A check of the form:
+//
+//	x, err := f()
+//	if err != nil {
+//		return 0, err
+//	}
+//
+// is rewritten as:
+//
+//	x := try.E1(f())
+//
+// provided that the returned values other than the error are zero values
+// of unnamed results or the named results themselves. Checks of the form "if err := f(); err != nil { ... }"
+// are rewritten as "try.E(f())". Checks that do more than return the error,
+// such as wrapping it, are left as is.
+//
+// Each function with rewritten checks has its error result named, if necessary,
+// and defers a call to try.Handle to store the recovered error into it:
+//
+//	func load(name string) (_ *Config, err error) {
+//		defer try.Handle(&err)
+//		...
+//	}
+//
+// The paths may be files or directories, which are processed recursively.
+// Without any paths, standard input is rewritten to standard output.
+//
+// The flags are:
+//
+//	-l
+//		List files whose contents would change.
+//	-w
+//		Write the result to the source file instead of standard output.
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/dsnet/try/internal/rewrite"
+)
+
+func main() {
+	rewrite.Main("tryconvert", convert)
+}
+
+// funcInfo describes the results of a function.
+type funcInfo struct {
+	nresults int
+	names    []string        // name of each result, or empty if unnamed
+	named    map[string]bool // names of the results
+	errName  string          // name of the error result, once named
+}
+
+// converter rewrites the error checks of a single file.
+type converter struct {
+	rewrite.File
+	uses map[*ast.Object][]*ast.Ident
+	try  string // name of package try within the file
+}
+
+// convert returns src with error checks rewritten into calls to the E functions
+// and reports the number of rewritten checks.
+func convert(filename string, src []byte) ([]byte, int, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, 0, err
+	}
+	c := &converter{File: rewrite.File{Fset: fset, Src: src}, uses: make(map[*ast.Object][]*ast.Ident), try: "try"}
+	imp, name := rewrite.TryImport(f)
+	if imp != nil {
+		c.try = name
+	}
+	if c.try == "." || c.try == "_" {
+		return src, 0, nil
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && id.Obj != nil {
+			c.uses[id.Obj] = append(c.uses[id.Obj], id)
+		}
+		return true
+	})
+
+	var n int
+	ast.Inspect(f, func(node ast.Node) bool {
+		switch fn := node.(type) {
+		case *ast.FuncDecl:
+			if fn.Body != nil {
+				n += c.function(fn.Type, fn.Body)
+			}
+		case *ast.FuncLit:
+			n += c.function(fn.Type, fn.Body)
+		}
+		return true
+	})
+	if n == 0 {
+		return src, 0, nil
+	}
+	if imp == nil {
+		c.addImport(f)
+	}
+
+	out, err := c.Apply()
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %v", filename, err)
+	}
+	out, err = format.Source(out)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: formatting rewritten source: %v", filename, err)
+	}
+	return out, n, nil
+}
+
+// function rewrites the error checks within the body of a function
+// whose last result is an error and reports the number of rewritten checks.
+func (c *converter) function(typ *ast.FuncType, body *ast.BlockStmt) int {
+	if typ.Results == nil {
+		return 0
+	}
+	results := typ.Results.List
+	last := results[len(results)-1]
+	if id, ok := last.Type.(*ast.Ident); !ok || id.Name != "error" {
+		return 0
+	}
+	fn := funcInfo{named: make(map[string]bool), errName: "err"}
+	for _, field := range results {
+		fn.nresults += max(len(field.Names), 1)
+		if len(field.Names) == 0 {
+			fn.names = append(fn.names, "")
+		}
+		for _, name := range field.Names {
+			fn.names = append(fn.names, name.Name)
+			fn.named[name.Name] = true
+		}
+	}
+	rename := true
+	if len(last.Names) > 0 {
+		if name := last.Names[len(last.Names)-1].Name; name != "_" {
+			fn.errName, rename = name, false
+		}
+	}
+	if rename && (fn.named[fn.errName] || declares(typ.Params, fn.errName)) {
+		return 0
+	}
+	errName := fn.errName
+
+	// Rewrite the error checks in each statement list
+	// outside of nested function literals.
+	var edits []rewrite.Edit
+	converted := make(map[ast.Stmt]bool)
+	ast.Inspect(body, func(n ast.Node) bool {
+		var list []ast.Stmt
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.BlockStmt:
+			list = n.List
+		case *ast.CaseClause:
+			list = n.Body
+		case *ast.CommClause:
+			list = n.Body
+		}
+		for i, stmt := range list {
+			var next ast.Stmt
+			if i+1 < len(list) {
+				next = list[i+1]
+			}
+			if e, ok := c.check(stmt, next, fn); ok {
+				edits = append(edits, e)
+				converted[stmt] = true
+			}
+		}
+		return true
+	})
+	n := len(edits)
+	if n == 0 {
+		return 0
+	}
+
+	if rename {
+		// Declarations of errName at the top level of the body
+		// would conflict with the named result.
+		for _, stmt := range body.List {
+			switch stmt := stmt.(type) {
+			case *ast.DeclStmt:
+				for _, spec := range stmt.Decl.(*ast.GenDecl).Specs {
+					if spec, ok := spec.(*ast.ValueSpec); ok && declares(&ast.FieldList{List: []*ast.Field{{Names: spec.Names}}}, errName) {
+						return 0
+					}
+				}
+			case *ast.AssignStmt:
+				if stmt.Tok == token.DEFINE && !converted[stmt] && onlyDeclares(stmt, errName) {
+					edits = append(edits, c.Replace(stmt.TokPos, stmt.TokPos+token.Pos(len(":=")), "="))
+				}
+			}
+		}
+
+		// Name the results so that the handler can store the error.
+		if len(last.Names) > 0 {
+			id := last.Names[len(last.Names)-1]
+			edits = append(edits, c.Replace(id.Pos(), id.End(), errName))
+		} else {
+			var fields []string
+			for _, field := range results[:len(results)-1] {
+				fields = append(fields, "_ "+c.Text(field.Type))
+			}
+			fields = append(fields, errName+" error")
+			edits = append(edits, c.Replace(typ.Results.Pos(), typ.Results.End(), "("+strings.Join(fields, ", ")+")"))
+		}
+	}
+	if !c.defersTry(body) {
+		edits = append(edits, c.Replace(body.Lbrace+1, body.Lbrace+1, "\ndefer "+c.try+".Handle(&"+errName+");"))
+	}
+	c.Edits = append(c.Edits, edits...)
+	return n
+}
+
+// check returns an edit that rewrites an error check consisting of stmt,
+// possibly followed by next, into a call to an E function.
+func (c *converter) check(stmt, next ast.Stmt, fn funcInfo) (rewrite.Edit, bool) {
+	// Rewrite "if err := f(); err != nil { return ..., err }" as try.E(f()).
+	if s, ok := stmt.(*ast.IfStmt); ok && s.Init != nil {
+		init, ok := s.Init.(*ast.AssignStmt)
+		if !ok || len(init.Lhs) != 1 || len(init.Rhs) != 1 {
+			return rewrite.Edit{}, false
+		}
+		call, ok := init.Rhs[0].(*ast.CallExpr)
+		errVar, ok2 := init.Lhs[0].(*ast.Ident)
+		if !ok || !ok2 || !c.returnsErr(s, errVar, init, fn) {
+			return rewrite.Edit{}, false
+		}
+		return c.Wrap(s.Pos(), s.End(), c.try+".E(", call, ")"), true
+	}
+
+	// Rewrite "x, err := f()" followed by "if err != nil { return ..., err }"
+	// as x := try.E1(f()).
+	assign, ok := stmt.(*ast.AssignStmt)
+	if !ok || len(assign.Rhs) != 1 || len(assign.Lhs) > 9 {
+		return rewrite.Edit{}, false
+	}
+	call, ok := assign.Rhs[0].(*ast.CallExpr)
+	errVar, ok2 := assign.Lhs[len(assign.Lhs)-1].(*ast.Ident)
+	s, ok3 := next.(*ast.IfStmt)
+	if !ok || !ok2 || !ok3 || s.Init != nil || !c.returnsErr(s, errVar, assign, fn) {
+		return rewrite.Edit{}, false
+	}
+	values := assign.Lhs[:len(assign.Lhs)-1]
+	if len(values) == 0 {
+		return c.Wrap(assign.Pos(), s.End(), c.try+".E(", call, ")"), true
+	}
+	var lhs []string
+	tok := token.ASSIGN
+	blank := true
+	for _, v := range values {
+		if id, ok := v.(*ast.Ident); ok && id.Obj != nil && id.Obj.Decl == assign {
+			tok = assign.Tok
+		}
+		if id, ok := v.(*ast.Ident); !ok || id.Name != "_" {
+			blank = false
+		}
+		lhs = append(lhs, c.Text(v))
+	}
+	if blank {
+		return rewrite.Edit{}, false // leave discarded values to be handled by hand
+	}
+	e := c.try + ".E" + strconv.Itoa(len(values))
+	return c.Wrap(assign.Pos(), s.End(), strings.Join(lhs, ", ")+" "+tok.String()+" "+e+"(", call, ")"), true
+}
+
+// returnsErr reports whether s is of the form "if err != nil { return ..., err }"
+// and has no else branch, where the values returned before the error are zero values
+// of unnamed or blank results, or the named results in their own positions.
+// A bare return is only accepted if errVar is the named error result.
+// If errVar is declared by decl, it must either be used
+// only within the check or have the name of the error result,
+// which is nil wherever the declared variable was after the check.
+func (c *converter) returnsErr(s *ast.IfStmt, errVar *ast.Ident, decl ast.Stmt, fn funcInfo) bool {
+	if errVar.Name == "_" || s.Else != nil || len(s.Body.List) != 1 {
+		return false
+	}
+	cond, ok := s.Cond.(*ast.BinaryExpr)
+	if !ok || cond.Op != token.NEQ || !rewrite.IsIdent(cond.X, errVar.Name) || !rewrite.IsIdent(cond.Y, "nil") {
+		return false
+	}
+	ret, ok := s.Body.List[0].(*ast.ReturnStmt)
+	if !ok {
+		return false
+	}
+	switch {
+	case len(ret.Results) == 0 && errVar.Name == fn.errName && isNamed(errVar, fn.named):
+	case len(ret.Results) == fn.nresults && rewrite.IsIdent(ret.Results[fn.nresults-1], errVar.Name):
+		// Named results keep their values when the handler stores the error,
+		// so only unnamed results, which become blank, may be returned as zero.
+		for i, v := range ret.Results[:fn.nresults-1] {
+			switch name := fn.names[i]; name {
+			case "", "_":
+				if !isZero(v) {
+					return false
+				}
+			default:
+				if !rewrite.IsIdent(v, name) || !isNamed(v, fn.named) {
+					return false
+				}
+			}
+		}
+	default:
+		return false
+	}
+
+	if errVar.Obj != nil && errVar.Obj.Decl == decl && errVar.Name != fn.errName {
+		for _, id := range c.uses[errVar.Obj] {
+			if id.Pos() < decl.Pos() || id.Pos() >= s.End() {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// defersTry reports whether body defers a call to a function in package try.
+func (c *converter) defersTry(body *ast.BlockStmt) bool {
+	for _, stmt := range body.List {
+		if d, ok := stmt.(*ast.DeferStmt); ok {
+			if sel, ok := d.Call.Fun.(*ast.SelectorExpr); ok && rewrite.IsIdent(sel.X, c.try) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// addImport adds an import of package try to f.
+func (c *converter) addImport(f *ast.File) {
+	for _, decl := range f.Decls {
+		if d, ok := decl.(*ast.GenDecl); ok && d.Tok == token.IMPORT {
+			// Start a new group of imports after the standard library.
+			text := strconv.Quote(rewrite.TryPath) + "\n"
+			if last := d.Specs[len(d.Specs)-1].(*ast.ImportSpec); isStd(last.Path.Value) {
+				text = "\n" + text
+			}
+			if d.Rparen.IsValid() {
+				c.Edits = append(c.Edits, c.Replace(d.Rparen, d.Rparen, text))
+			} else {
+				spec := d.Specs[0]
+				c.Edits = append(c.Edits, c.Replace(spec.Pos(), spec.End(), "(\n"+c.Text(spec)+"\n"+text+")"))
+			}
+			return
+		}
+	}
+	c.Edits = append(c.Edits, c.Replace(f.Name.End(), f.Name.End(), "\n\nimport "+strconv.Quote(rewrite.TryPath)))
+}
+
+// declares reports whether fields declares name.
+func declares(fields *ast.FieldList, name string) bool {
+	for _, field := range fields.List {
+		for _, id := range field.Names {
+			if id.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// onlyDeclares reports whether name is the only new variable declared by stmt.
+func onlyDeclares(stmt *ast.AssignStmt, name string) bool {
+	var found bool
+	for _, v := range stmt.Lhs {
+		if id, ok := v.(*ast.Ident); ok && id.Obj != nil && id.Obj.Decl == stmt {
+			if id.Name != name {
+				return false
+			}
+			found = true
+		}
+	}
+	return found
+}
+
+// isStd reports whether the quoted import path is in the standard library.
+func isStd(path string) bool {
+	path, _ = strconv.Unquote(path)
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
+
+// isZero reports whether expr is a literal zero value.
+// Empty slice and map literals are not, since they are not nil.
+func isZero(expr ast.Expr) bool {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return expr.Name == "nil" || expr.Name == "false"
+	case *ast.BasicLit:
+		return expr.Value == "0" || expr.Value == "0.0" || expr.Value == `""`
+	case *ast.CompositeLit:
+		switch typ := expr.Type.(type) {
+		case *ast.ArrayType:
+			if typ.Len == nil {
+				return false
+			}
+		case *ast.MapType:
+			return false
+		}
+		return len(expr.Elts) == 0
+	}
+	return false
+}
+
+// isNamed reports whether expr refers to one of the named results.
+func isNamed(expr ast.Expr, named map[string]bool) bool {
+	id, ok := expr.(*ast.Ident)
+	if !ok || id.Obj == nil || !named[id.Name] {
+		return false
+	}
+	_, ok = id.Obj.Decl.(*ast.Field)
+	return ok
+}
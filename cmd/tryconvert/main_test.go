@@ -0,0 +1,279 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		want  string
+		wantN int
+	}{{
+		name: "E1",
+		in: `package p
+
+import "os"
+
+func read(name string) ([]byte, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+`,
+		want: `package p
+
+import (
+	"os"
+
+	"github.com/dsnet/try"
+)
+
+func read(name string) (_ []byte, err error) {
+	defer try.Handle(&err)
+	b := try.E1(os.ReadFile(name))
+	return b, nil
+}
+`,
+		wantN: 1,
+	}, {
+		name: "IfInit",
+		in: `package p
+
+import (
+	"os"
+)
+
+func remove(names ...string) error {
+	for _, name := range names {
+		if err := os.Remove(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+`,
+		want: `package p
+
+import (
+	"os"
+
+	"github.com/dsnet/try"
+)
+
+func remove(names ...string) (err error) {
+	defer try.Handle(&err)
+	for _, name := range names {
+		try.E(os.Remove(name))
+	}
+	return nil
+}
+`,
+		wantN: 1,
+	}, {
+		name: "NamedResults",
+		in: `package p
+
+import t "github.com/dsnet/try"
+
+func stat() (n int, err error) {
+	n, err = count()
+	if err != nil {
+		return
+	}
+	m, err := count()
+	if err != nil {
+		return n, err
+	}
+	return n + m, nil
+}
+`,
+		want: `package p
+
+import t "github.com/dsnet/try"
+
+func stat() (n int, err error) {
+	defer t.Handle(&err)
+	n = t.E1(count())
+	m := t.E1(count())
+	return n + m, nil
+}
+`,
+		wantN: 2,
+	}, {
+		name: "Unconvertible",
+		in: `package p
+
+import "fmt"
+
+func wrapped() (int, error) {
+	n, err := count()
+	if err != nil {
+		return 0, fmt.Errorf("count: %w", err)
+	}
+	return n, nil
+}
+
+func nonZero() (int, error) {
+	n, err := count()
+	if err != nil {
+		return -1, err
+	}
+	return n, nil
+}
+
+func reused() error {
+	_, err := count()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func namedZero() (n int, err error) {
+	n = 5
+	x, err := count()
+	if err != nil {
+		return 0, err
+	}
+	return n + x, nil
+}
+
+func bareOther() (v int, err error) {
+	x, e := count()
+	if e != nil {
+		return
+	}
+	return x, nil
+}
+
+func emptySlice() ([]int, error) {
+	n, err := count()
+	if err != nil {
+		return []int{}, err
+	}
+	return make([]int, n), nil
+}
+
+func noError() int {
+	n, err := count()
+	if err != nil {
+		return 0
+	}
+	return n
+}
+`,
+		wantN: 0,
+	}, {
+		name: "Redeclared",
+		in: `package p
+
+func f() error {
+	n, err2 := count()
+	if err2 != nil {
+		return err2
+	}
+	err := check(n)
+	log(err)
+	return nil
+}
+`,
+		want: `package p
+
+import "github.com/dsnet/try"
+
+func f() (err error) {
+	defer try.Handle(&err)
+	n := try.E1(count())
+	err = check(n)
+	log(err)
+	return nil
+}
+`,
+		wantN: 1,
+	}, {
+		name: "Closure",
+		in: `package p
+
+func f() {
+	run(func() error {
+		if err := check(0); err != nil {
+			return err
+		}
+		return nil
+	})
+}
+`,
+		want: `package p
+
+import "github.com/dsnet/try"
+
+func f() {
+	run(func() (err error) {
+		defer try.Handle(&err)
+		try.E(check(0))
+		return nil
+	})
+}
+`,
+		wantN: 1,
+	}, {
+		name: "NestedClosure",
+		in: `package p
+
+func count(run func(func() error) (int, error), g func() error) (int, error) {
+	n, err := run(func() error { if err := g(); err != nil { return err }; return nil })
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+`,
+		want: `package p
+
+import "github.com/dsnet/try"
+
+func count(run func(func() error) (int, error), g func() error) (_ int, err error) {
+	defer try.Handle(&err)
+	n := try.E1(run(func() (err error) {
+		defer try.Handle(&err)
+		try.E(g())
+		return nil
+	}))
+	return n, nil
+}
+`,
+		wantN: 2,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, n, err := convert("p.go", []byte(tt.in))
+			if err != nil {
+				t.Fatalf("convert error: %v", err)
+			}
+			if n != tt.wantN {
+				t.Errorf("convert reported %d checks, want %d", n, tt.wantN)
+			}
+			if tt.want == "" {
+				tt.want = tt.in
+			}
+			if string(got) != tt.want {
+				t.Errorf("convert mismatch:\ngot:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertInvalid(t *testing.T) {
+	if _, _, err := convert("p.go", []byte("package p\nfunc {")); err == nil || !strings.Contains(err.Error(), "p.go") {
+		t.Errorf("convert error = %v, want syntax error in p.go", err)
+	}
+}
@@ -0,0 +1,530 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+// Command tryexpand rewrites calls to the E functions of package try
+// into explicit error checks. It is the inverse of tryconvert.
+//
+// Usage:
+//
+//	tryexpand [flags] [path ...]
+//
+// Calls are only expanded in functions that defer a call to try.Handle
+// with a pointer to their named error result, in which case a statement of the form:
+//
+//	x := try.E1(f())
+//
+// is rewritten as:
+//
+//	x, err := f()
+//	if err != nil {
+//		return n, err
+//	}
+//
+// where the other named results are returned as is, which is what the caller
+// of the function observed in case of a panic. Blank results are returned as
+// zero values. Calls to try.E are rewritten as "if err := f(); err != nil { ... }".
+// Once nothing within a function may panic with an error from an E function,
+// its deferred call to try.Handle is removed, as is the import of package try
+// once unused. Methods named E, such as Result.E, and functions of the package
+// (in the same directory) that call E functions without a handler of their own
+// are assumed to panic, so that the handler is kept for them.
+//
+// Calls in other positions, such as nested within expressions,
+// are reported to standard error and left as is.
+//
+// The paths may be files or directories, which are processed recursively.
+// Without any paths, standard input is rewritten to standard output.
+//
+// The flags are:
+//
+//	-l
+//		List files whose contents would change.
+//	-w
+//		Write the result to the source file instead of standard output.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/dsnet/try/internal/rewrite"
+)
+
+func main() {
+	rewrite.Main("tryexpand", func(filename string, src []byte) ([]byte, int, error) {
+		return expand(filename, src, os.Stderr)
+	})
+}
+
+// expander rewrites the calls to E functions of a single file.
+type expander struct {
+	rewrite.File
+	file *ast.File
+	try  string // name of package try within the file
+	warn io.Writer
+
+	// helpers are the names of the functions and methods in the package
+	// that may panic with errors from the E functions to their callers.
+	helpers map[string]bool
+}
+
+// expand returns src with calls to the E functions rewritten into error checks
+// and reports the number of rewritten calls.
+// Calls that cannot be rewritten are reported to warn.
+func expand(filename string, src []byte, warn io.Writer) ([]byte, int, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, 0, err
+	}
+	e := &expander{File: rewrite.File{Fset: fset, Src: src}, file: f, warn: warn}
+	imp, name := rewrite.TryImport(f)
+	if imp == nil || name == "." || name == "_" {
+		return src, 0, nil
+	}
+	e.try = name
+	e.helpers = helpers(filename, f)
+
+	var n int
+	ast.Inspect(f, func(node ast.Node) bool {
+		switch fn := node.(type) {
+		case *ast.FuncDecl:
+			if fn.Body != nil {
+				n += e.function(fn.Type, fn.Body)
+			}
+		case *ast.FuncLit:
+			n += e.function(fn.Type, fn.Body)
+		}
+		return true
+	})
+	if n == 0 {
+		return src, 0, nil
+	}
+	if !e.uses(f, imp) {
+		e.removeImport(imp)
+	}
+
+	out, err := e.Apply()
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %v", filename, err)
+	}
+	out, err = format.Source(out)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: formatting rewritten source: %v", filename, err)
+	}
+	return out, n, nil
+}
+
+// function rewrites the calls to E functions in the body of a function
+// that defers try.Handle for its named error result and reports the number
+// of rewritten calls.
+func (e *expander) function(typ *ast.FuncType, body *ast.BlockStmt) int {
+	handler, errName := e.handler(typ, body)
+	if handler == nil {
+		return 0
+	}
+
+	// Returned values for each result, of which the last is the error.
+	var rets []string
+	for _, field := range typ.Results.List {
+		for _, name := range field.Names {
+			if name.Name == "_" {
+				rets = append(rets, e.zero(field.Type))
+			} else {
+				rets = append(rets, name.Name)
+			}
+		}
+	}
+	rets[len(rets)-1] = errName
+	ret := "return " + strings.Join(rets, ", ")
+
+	var edits []rewrite.Edit
+	expanded := make(map[*ast.CallExpr]bool)
+	walk(body, func(list []ast.Stmt) {
+		for _, stmt := range list {
+			switch stmt := stmt.(type) {
+			case *ast.ExprStmt:
+				call, n := e.callE(stmt.X)
+				if call == nil || n != 0 {
+					continue
+				}
+				text := "if " + errName + " := "
+				post := "; " + errName + " != nil {\n" + ret + "\n}"
+				edits = append(edits, e.Wrap(stmt.Pos(), stmt.End(), text, call.Args[0], post))
+				expanded[call] = true
+			case *ast.AssignStmt:
+				if len(stmt.Rhs) != 1 {
+					continue
+				}
+				call, n := e.callE(stmt.Rhs[0])
+				if call == nil || n != len(stmt.Lhs) || n == 0 {
+					continue
+				}
+				var lhs []string
+				for _, v := range stmt.Lhs {
+					lhs = append(lhs, e.Text(v))
+				}
+				lhs = append(lhs, errName)
+				text := strings.Join(lhs, ", ") + " " + stmt.Tok.String() + " "
+				post := "\nif " + errName + " != nil {\n" + ret + "\n}"
+				edits = append(edits, e.Wrap(stmt.Pos(), stmt.End(), text, call.Args[0], post))
+				expanded[call] = true
+			}
+		}
+	})
+
+	// Report the calls that remain unexpanded.
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.CallExpr:
+			if call, _ := e.callE(n); call != nil && !expanded[call] {
+				fmt.Fprintf(e.warn, "%v: cannot expand call to %s\n", e.Fset.Position(call.Pos()), e.Text(call.Fun))
+			}
+		}
+		return true
+	})
+
+	// Keep the handler if anything else may still panic into it,
+	// including function literals without handlers of their own,
+	// methods such as Result.E, and functions in the package that
+	// call E functions without a handler.
+	keep := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			if h, _ := e.handler(n.Type, n.Body); h != nil {
+				return false
+			}
+		case *ast.CallExpr:
+			keep = !expanded[n] && mayRaise(n, e.try, e.helpers)
+		}
+		return !keep
+	})
+	if !keep {
+		edits = append(edits, e.deleteLines(handler))
+	}
+	e.Edits = append(e.Edits, edits...)
+	return len(expanded)
+}
+
+// handler returns the statement at the top level of body that defers
+// try.Handle with a pointer to the named error result of a function of type typ,
+// and the name of that result.
+func (e *expander) handler(typ *ast.FuncType, body *ast.BlockStmt) (ast.Stmt, string) {
+	if typ.Results == nil {
+		return nil, ""
+	}
+	last := typ.Results.List[len(typ.Results.List)-1]
+	if len(last.Names) == 0 || !rewrite.IsIdent(last.Type, "error") {
+		return nil, ""
+	}
+	errName := last.Names[len(last.Names)-1].Name
+	for _, stmt := range body.List {
+		d, ok := stmt.(*ast.DeferStmt)
+		if !ok || len(d.Call.Args) != 1 {
+			continue
+		}
+		sel, ok := d.Call.Fun.(*ast.SelectorExpr)
+		if !ok || !rewrite.IsIdent(sel.X, e.try) || sel.Sel.Name != "Handle" {
+			continue
+		}
+		if u, ok := d.Call.Args[0].(*ast.UnaryExpr); ok && u.Op == token.AND && rewrite.IsIdent(u.X, errName) {
+			return stmt, errName
+		}
+	}
+	return nil, ""
+}
+
+// callE returns expr and the number of values returned if it is a call to
+// try.E or one of try.E1 to try.E8 with a single argument (e.g., "try.E1(f())").
+func (e *expander) callE(expr ast.Expr) (*ast.CallExpr, int) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return nil, -1
+	}
+	sel, ok := stripIndex(call.Fun).(*ast.SelectorExpr)
+	if !ok || !rewrite.IsIdent(sel.X, e.try) || !strings.HasPrefix(sel.Sel.Name, "E") {
+		return nil, -1
+	}
+	if sel.Sel.Name == "E" {
+		return call, 0
+	}
+	if n, err := strconv.Atoi(sel.Sel.Name[len("E"):]); err == nil && 1 <= n && n <= 8 {
+		return call, n
+	}
+	return nil, -1
+}
+
+// zero returns the zero value of the type expressed by typ.
+func (e *expander) zero(typ ast.Expr) string {
+	switch typ := typ.(type) {
+	case *ast.Ident:
+		switch typ.Name {
+		case "bool":
+			return "false"
+		case "string":
+			return `""`
+		case "error", "any":
+			return "nil"
+		case "int", "int8", "int16", "int32", "int64",
+			"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+			"byte", "rune", "float32", "float64", "complex64", "complex128":
+			return "0"
+		}
+		if spec := e.typeSpec(typ.Name); spec != nil && spec.TypeParams == nil && spec.Assign == token.NoPos {
+			switch spec.Type.(type) {
+			case *ast.StructType:
+				return typ.Name + "{}"
+			case *ast.ArrayType:
+				if z := e.zero(spec.Type); z != "nil" {
+					return typ.Name + "{}"
+				}
+				return "nil"
+			case *ast.StarExpr, *ast.MapType, *ast.ChanType, *ast.FuncType, *ast.InterfaceType:
+				return "nil"
+			case *ast.Ident:
+				if z := e.zero(spec.Type); !strings.HasPrefix(z, "*new(") {
+					return z
+				}
+			}
+		}
+	case *ast.StarExpr, *ast.MapType, *ast.ChanType, *ast.FuncType, *ast.InterfaceType:
+		return "nil"
+	case *ast.ArrayType:
+		if typ.Len == nil {
+			return "nil"
+		}
+		return e.Text(typ) + "{}"
+	case *ast.StructType:
+		return e.Text(typ) + "{}"
+	}
+	return "*new(" + e.Text(typ) + ")"
+}
+
+// typeSpec returns the declaration of the named type in the file, if any.
+func (e *expander) typeSpec(name string) *ast.TypeSpec {
+	for _, decl := range e.file.Decls {
+		if d, ok := decl.(*ast.GenDecl); ok && d.Tok == token.TYPE {
+			for _, spec := range d.Specs {
+				if spec := spec.(*ast.TypeSpec); spec.Name.Name == name {
+					return spec
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// uses reports whether package try is referenced within f other than by imp.
+func (e *expander) uses(f *ast.File, imp *ast.ImportSpec) bool {
+	var found bool
+	ast.Inspect(f, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok && rewrite.IsIdent(sel.X, e.try) {
+			found = true
+		}
+		return !found
+	})
+	if found {
+		// References may have been removed by the edits.
+		out, err := e.Apply()
+		if err != nil {
+			return true
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), "", out, 0)
+		if err != nil {
+			return true
+		}
+		found = false
+		ast.Inspect(f, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok && rewrite.IsIdent(sel.X, e.try) {
+				found = true
+			}
+			return !found
+		})
+	}
+	return found
+}
+
+// removeImport removes the import of package try.
+func (e *expander) removeImport(imp *ast.ImportSpec) {
+	for _, decl := range e.file.Decls {
+		if d, ok := decl.(*ast.GenDecl); ok && d.Tok == token.IMPORT {
+			for _, spec := range d.Specs {
+				if spec == imp {
+					if len(d.Specs) == 1 {
+						e.Edits = append(e.Edits, e.deleteLines(d))
+					} else {
+						e.Edits = append(e.Edits, e.deleteLines(spec))
+					}
+					return
+				}
+			}
+		}
+	}
+}
+
+// deleteLines returns an edit deleting the lines spanned by node,
+// or only node and a following semicolon if it shares its lines
+// with other source text.
+func (e *expander) deleteLines(node ast.Node) rewrite.Edit {
+	tf := e.Fset.File(node.Pos())
+	start := tf.LineStart(tf.Line(node.Pos()))
+	end := tf.Offset(node.End())
+	for end < len(e.Src) && (e.Src[end] == ' ' || e.Src[end] == '\t') {
+		end++
+	}
+	before := e.Src[tf.Offset(start):tf.Offset(node.Pos())]
+	if len(bytes.TrimSpace(before)) > 0 || end < len(e.Src) && e.Src[end] != '\n' && !bytes.HasPrefix(e.Src[end:], []byte("//")) {
+		if end < len(e.Src) && e.Src[end] == ';' {
+			end++
+		}
+		return e.Replace(node.Pos(), tf.Pos(end), "")
+	}
+	if line := tf.Line(node.End()); line < tf.LineCount() {
+		return e.Replace(start, tf.LineStart(line+1), "")
+	}
+	return e.Replace(start, node.End(), "")
+}
+
+// walk calls fn with each statement list in body outside of nested function literals.
+func walk(body *ast.BlockStmt, fn func([]ast.Stmt)) {
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.BlockStmt:
+			fn(n.List)
+		case *ast.CaseClause:
+			fn(n.Body)
+		case *ast.CommClause:
+			fn(n.Body)
+		}
+		return true
+	})
+}
+
+// helpers returns the names of the functions and methods declared in f
+// and the other files of its package in the directory of filename, if any,
+// that may panic with errors from the E functions to their callers,
+// either directly or by calling other such functions.
+// Calls are matched by name only, so the result is approximate.
+func helpers(filename string, f *ast.File) map[string]bool {
+	files := []*ast.File{f}
+	if _, err := os.Stat(filename); err == nil {
+		paths, _ := filepath.Glob(filepath.Join(filepath.Dir(filename), "*.go"))
+		for _, path := range paths {
+			if filepath.Clean(path) == filepath.Clean(filename) {
+				continue
+			}
+			if g, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.SkipObjectResolution); err == nil && g.Name.Name == f.Name.Name {
+				files = append(files, g)
+			}
+		}
+	}
+
+	// Determine which functions raise directly and which functions they call.
+	raising := make(map[string]bool)
+	calls := make(map[string][]string)
+	for _, f := range files {
+		_, name := rewrite.TryImport(f)
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil || recovers(fn.Body, name) {
+				continue
+			}
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				switch n := n.(type) {
+				case *ast.FuncLit:
+					return !recovers(n.Body, name)
+				case *ast.CallExpr:
+					switch fun := stripIndex(n.Fun).(type) {
+					case *ast.Ident:
+						calls[fn.Name.Name] = append(calls[fn.Name.Name], fun.Name)
+					case *ast.SelectorExpr:
+						calls[fn.Name.Name] = append(calls[fn.Name.Name], fun.Sel.Name)
+					}
+					if mayRaise(n, name, nil) {
+						raising[fn.Name.Name] = true
+					}
+				}
+				return true
+			})
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for caller, callees := range calls {
+			for _, callee := range callees {
+				if !raising[caller] && raising[callee] {
+					raising[caller] = true
+					changed = true
+				}
+			}
+		}
+	}
+	return raising
+}
+
+// recovers reports whether body defers a handler of package try,
+// which is referenced by name, at its top level.
+func recovers(body *ast.BlockStmt, name string) bool {
+	for _, stmt := range body.List {
+		if d, ok := stmt.(*ast.DeferStmt); ok {
+			if sel, ok := d.Call.Fun.(*ast.SelectorExpr); ok && rewrite.IsIdent(sel.X, name) && name != "" &&
+				(sel.Sel.Name == "F" || strings.HasPrefix(sel.Sel.Name, "Handle") || strings.HasPrefix(sel.Sel.Name, "Recover")) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mayRaise reports whether call may panic with an error from an E function,
+// where package try is referenced by name and helpers are the names of
+// functions that may do so.
+// Methods named E, such as Result.E, are assumed to be those of package try.
+func mayRaise(call *ast.CallExpr, name string, helpers map[string]bool) bool {
+	switch fun := stripIndex(call.Fun).(type) {
+	case *ast.Ident:
+		return helpers[fun.Name]
+	case *ast.SelectorExpr:
+		if name != "" && rewrite.IsIdent(fun.X, name) {
+			return raises(fun.Sel.Name)
+		}
+		return fun.Sel.Name == "E" || helpers[fun.Sel.Name]
+	}
+	return false
+}
+
+// stripIndex returns the function of an instantiation (e.g., "try.E1[int]").
+func stripIndex(fun ast.Expr) ast.Expr {
+	switch idx := fun.(type) {
+	case *ast.IndexExpr:
+		return idx.X
+	case *ast.IndexListExpr:
+		return idx.X
+	}
+	return fun
+}
+
+// raises reports whether the function of package try with the given name
+// may panic for a deferred handler to recover.
+func raises(name string) bool {
+	switch name {
+	case "OK", "OK1", "MapGet", "Assert", "Parallel", "Recv", "MapConcurrent":
+		return true
+	}
+	return strings.HasPrefix(name, "E")
+}
@@ -0,0 +1,292 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		want     string
+		wantN    int
+		wantWarn string
+	}{{
+		name: "E1",
+		in: `package p
+
+import (
+	"os"
+
+	"github.com/dsnet/try"
+)
+
+func read(name string) (_ []byte, err error) {
+	defer try.Handle(&err)
+	b := try.E1(os.ReadFile(name))
+	try.E(check(b))
+	return b, nil
+}
+`,
+		want: `package p
+
+import (
+	"os"
+)
+
+func read(name string) (_ []byte, err error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := check(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+`,
+		wantN: 2,
+	}, {
+		name: "ZeroValues",
+		in: `package p
+
+import t "github.com/dsnet/try"
+
+type config struct{ name string }
+
+type id int
+
+func load() (_ config, _ id, _ string, _ [2]int, _ *config, n int, err error) {
+	defer t.Handle(&err)
+	n = t.E1(count())
+	return
+}
+`,
+		want: `package p
+
+type config struct{ name string }
+
+type id int
+
+func load() (_ config, _ id, _ string, _ [2]int, _ *config, n int, err error) {
+	n, err = count()
+	if err != nil {
+		return config{}, 0, "", [2]int{}, nil, n, err
+	}
+	return
+}
+`,
+		wantN: 1,
+	}, {
+		name: "Unexpandable",
+		in: `package p
+
+import "github.com/dsnet/try"
+
+func f() (err error) {
+	defer try.Handle(&err)
+	x := try.E1(count())
+	use(try.E1(count()))
+	run(func() {
+		try.E(check(x))
+	})
+	return nil
+}
+`,
+		want: `package p
+
+import "github.com/dsnet/try"
+
+func f() (err error) {
+	defer try.Handle(&err)
+	x, err := count()
+	if err != nil {
+		return err
+	}
+	use(try.E1(count()))
+	run(func() {
+		try.E(check(x))
+	})
+	return nil
+}
+`,
+		wantN:    1,
+		wantWarn: "p.go:8:6: cannot expand call to try.E1\n",
+	}, {
+		name: "OwnHandler",
+		in: `package p
+
+import "github.com/dsnet/try"
+
+func f() {
+	run(func() (err error) {
+		defer try.Handle(&err)
+		try.E(check(0))
+		return nil
+	})
+}
+`,
+		want: `package p
+
+func f() {
+	run(func() (err error) {
+		if err := check(0); err != nil {
+			return err
+		}
+		return nil
+	})
+}
+`,
+		wantN: 1,
+	}, {
+		name: "NoHandler",
+		in: `package p
+
+import "github.com/dsnet/try"
+
+func f() error {
+	try.E(check(0))
+	return nil
+}
+
+func g() (err error) {
+	defer try.HandleF(&err, func() {})
+	try.E(check(0))
+	return nil
+}
+`,
+		wantN: 0,
+	}, {
+		name: "NestedClosure",
+		in: `package p
+
+import "github.com/dsnet/try"
+
+func count(run func(func() error) (int, error), g func() error) (n int, err error) {
+	defer try.Handle(&err)
+	n = try.E1(run(func() (err error) { defer try.Handle(&err); try.E(g()); return nil }))
+	return n, nil
+}
+`,
+		want: `package p
+
+func count(run func(func() error) (int, error), g func() error) (n int, err error) {
+	n, err = run(func() (err error) {
+		if err := g(); err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return n, err
+	}
+	return n, nil
+}
+`,
+		wantN: 2,
+	}, {
+		name: "KeepForHelpers",
+		in: `package p
+
+import "github.com/dsnet/try"
+
+func sum(r try.Result[int]) (n int, err error) {
+	defer try.Handle(&err)
+	n = try.E1(count())
+	n += helper()
+	n += r.E()
+	return n, nil
+}
+
+func helper() int {
+	return indirect()
+}
+
+func indirect() int {
+	return try.E1(count())
+}
+`,
+		want: `package p
+
+import "github.com/dsnet/try"
+
+func sum(r try.Result[int]) (n int, err error) {
+	defer try.Handle(&err)
+	n, err = count()
+	if err != nil {
+		return n, err
+	}
+	n += helper()
+	n += r.E()
+	return n, nil
+}
+
+func helper() int {
+	return indirect()
+}
+
+func indirect() int {
+	return try.E1(count())
+}
+`,
+		wantN: 1,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var warn strings.Builder
+			got, n, err := expand("p.go", []byte(tt.in), &warn)
+			if err != nil {
+				t.Fatalf("expand error: %v", err)
+			}
+			if n != tt.wantN {
+				t.Errorf("expand reported %d calls, want %d", n, tt.wantN)
+			}
+			if tt.want == "" {
+				tt.want = tt.in
+			}
+			if string(got) != tt.want {
+				t.Errorf("expand mismatch:\ngot:\n%s\nwant:\n%s", got, tt.want)
+			}
+			if warn.String() != tt.wantWarn {
+				t.Errorf("expand warnings = %q, want %q", warn.String(), tt.wantWarn)
+			}
+		})
+	}
+}
+
+func TestExpandHelpers(t *testing.T) {
+	dir := t.TempDir()
+	helper := "package p\n\nimport t \"github.com/dsnet/try\"\n\nfunc helper() int { return t.E1(count()) }\n"
+	if err := os.WriteFile(filepath.Join(dir, "helper.go"), []byte(helper), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	src := `package p
+
+import "github.com/dsnet/try"
+
+func sum() (n int, err error) {
+	defer try.Handle(&err)
+	n = try.E1(count())
+	return n + helper(), nil
+}
+`
+	name := filepath.Join(dir, "p.go")
+	if err := os.WriteFile(name, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, _, err := expand(name, []byte(src), io.Discard)
+	if err != nil {
+		t.Fatalf("expand error: %v", err)
+	}
+	if !strings.Contains(string(got), "defer try.Handle(&err)") {
+		t.Errorf("expand removed the handler despite the call to helper:\n%s", got)
+	}
+}
@@ -0,0 +1,260 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+// Command trygen generates project-local E helpers for arities
+// and signatures not provided by package try.
+//
+// Usage:
+//
+//	trygen [flags] [signature ...]
+//
+// Each signature is a function name with optional type parameters and
+// a list of value types preceding the final error, for example:
+//
+//	ECancel[T any](T, func())
+//
+// which generates:
+//
+//	func ECancel[T any](v1 T, v2 func(), err error) (T, func())
+//
+// Typical use is through a go:generate directive in the target package:
+//
+//	//go:generate go run github.com/dsnet/try/cmd/trygen -arity=9-10 "ECancel[T any](T, func())"
+//
+// The generated helpers call try.ESkip, so that errors are attributed
+// to the callers of the helpers rather than to the generated file.
+//
+// The flags are:
+//
+//	-arity list
+//		Comma-separated arities or ranges of arities (e.g., "9,12-14")
+//		for which to generate generic EN helpers.
+//	-import path
+//		Additional import path needed by the signatures.
+//		It may be specified multiple times.
+//	-o file
+//		Output file (default "try_gen.go").
+//	-pkg name
+//		Package name of the output file (default $GOPACKAGE).
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type stringsFlag []string
+
+func (s *stringsFlag) String() string     { return strings.Join(*s, ",") }
+func (s *stringsFlag) Set(v string) error { *s = append(*s, v); return nil }
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("trygen: ")
+
+	var imports stringsFlag
+	arity := flag.String("arity", "", "comma-separated arities or ranges of arities")
+	output := flag.String("o", "try_gen.go", "output file")
+	pkg := flag.String("pkg", os.Getenv("GOPACKAGE"), "package name of the output file")
+	flag.Var(&imports, "import", "additional import path (may be repeated)")
+	flag.Parse()
+
+	if *pkg == "" {
+		log.Fatal("package name unknown; specify -pkg or run via go:generate")
+	}
+	arities, err := parseArities(*arity)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var sigs []signature
+	for _, arg := range flag.Args() {
+		sig, err := parseSignature(arg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		sigs = append(sigs, sig)
+	}
+	for _, n := range arities {
+		sigs = append(sigs, aritySignature(n))
+	}
+	if len(sigs) == 0 {
+		log.Fatal("nothing to generate; specify -arity or a signature")
+	}
+
+	b, err := generate(*pkg, imports, sigs)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*output, b, 0664); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// signature is the description of a single generated helper.
+type signature struct {
+	Name       string
+	TypeParams string   // e.g., "A, B any"; empty if not generic
+	Params     []string // names of each value
+	Types      []string // types of each value
+}
+
+// aritySignature returns the signature of a generic helper with n values.
+func aritySignature(n int) signature {
+	sig := signature{Name: "E" + strconv.Itoa(n)}
+	var tparams []string
+	for i := 1; i <= n; i++ {
+		tparams = append(tparams, "T"+strconv.Itoa(i))
+		sig.Params = append(sig.Params, "v"+strconv.Itoa(i))
+		sig.Types = append(sig.Types, "T"+strconv.Itoa(i))
+	}
+	sig.TypeParams = strings.Join(tparams, ", ") + " any"
+	return sig
+}
+
+// parseSignature parses a signature of the form "Name[TypeParams](Types)".
+func parseSignature(s string) (signature, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", "package p; func "+s, 0)
+	if err != nil {
+		return signature{}, fmt.Errorf("invalid signature %q: %v", s, err)
+	}
+	fd, ok := f.Decls[0].(*ast.FuncDecl)
+	if !ok || len(f.Decls) != 1 || fd.Recv != nil || fd.Body != nil || fd.Type.Results != nil {
+		return signature{}, fmt.Errorf("invalid signature %q: must be of the form Name[TypeParams](Types)", s)
+	}
+
+	sig := signature{Name: fd.Name.Name}
+	if tps := fd.Type.TypeParams; tps != nil {
+		var fields []string
+		for _, field := range tps.List {
+			var names []string
+			for _, name := range field.Names {
+				names = append(names, name.Name)
+			}
+			fields = append(fields, strings.Join(names, ", ")+" "+formatNode(fset, field.Type))
+		}
+		sig.TypeParams = strings.Join(fields, ", ")
+	}
+	for _, field := range fd.Type.Params.List {
+		typ := formatNode(fset, field.Type)
+		if len(field.Names) == 0 {
+			sig.Params = append(sig.Params, "v"+strconv.Itoa(len(sig.Params)+1))
+			sig.Types = append(sig.Types, typ)
+		}
+		for _, name := range field.Names {
+			sig.Params = append(sig.Params, name.Name)
+			sig.Types = append(sig.Types, typ)
+		}
+	}
+	if len(sig.Params) == 0 {
+		return signature{}, fmt.Errorf("invalid signature %q: must have at least one value", s)
+	}
+	for _, name := range sig.Params {
+		if name == "err" {
+			return signature{}, fmt.Errorf("invalid signature %q: value must not be named err", s)
+		}
+	}
+	return sig, nil
+}
+
+// parseArities parses a comma-separated list of arities or ranges of arities.
+func parseArities(s string) ([]int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var ns []int
+	for _, part := range strings.Split(s, ",") {
+		lo, hi, isRange := strings.Cut(part, "-")
+		if !isRange {
+			hi = lo
+		}
+		nlo, err1 := strconv.Atoi(strings.TrimSpace(lo))
+		nhi, err2 := strconv.Atoi(strings.TrimSpace(hi))
+		if err1 != nil || err2 != nil || nlo < 1 || nlo > nhi {
+			return nil, fmt.Errorf("invalid arity %q", part)
+		}
+		for n := nlo; n <= nhi; n++ {
+			ns = append(ns, n)
+		}
+	}
+	return ns, nil
+}
+
+// generate returns the formatted source of a Go file declaring sigs.
+func generate(pkg string, imports []string, sigs []signature) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("// Code generated by trygen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	b.WriteString("import (\n")
+	for _, path := range imports {
+		fmt.Fprintf(&b, "\t%q\n", path)
+	}
+	if len(imports) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString("\t\"github.com/dsnet/try\"\n)\n")
+
+	seen := make(map[string]bool)
+	for _, sig := range sigs {
+		if seen[sig.Name] {
+			return nil, fmt.Errorf("duplicate helper %s", sig.Name)
+		}
+		seen[sig.Name] = true
+
+		var params []string
+		for i := range sig.Params {
+			params = append(params, sig.Params[i]+" "+sig.Types[i])
+		}
+		var tparams string
+		if sig.TypeParams != "" {
+			tparams = "[" + sig.TypeParams + "]"
+		}
+		results := strings.Join(sig.Types, ", ")
+		if len(sig.Types) > 1 {
+			results = "(" + results + ")"
+		}
+
+		b.WriteString("\n")
+		fmt.Fprintf(&b, "// %s returns %s as is.\n", sig.Name, joinList(sig.Params))
+		b.WriteString("// It panics if err is non-nil.\n")
+		fmt.Fprintf(&b, "func %s%s(%s, err error) %s {\n", sig.Name, tparams, strings.Join(params, ", "), results)
+		b.WriteString("\ttry.ESkip(1, err)\n")
+		fmt.Fprintf(&b, "\treturn %s\n", strings.Join(sig.Params, ", "))
+		b.WriteString("}\n")
+	}
+
+	out, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated source: %v", err)
+	}
+	return out, nil
+}
+
+// joinList returns a list of names in English, e.g., "a, b, and c".
+func joinList(names []string) string {
+	switch len(names) {
+	case 1:
+		return names[0]
+	case 2:
+		return names[0] + " and " + names[1]
+	default:
+		return strings.Join(names[:len(names)-1], ", ") + ", and " + names[len(names)-1]
+	}
+}
+
+func formatNode(fset *token.FileSet, node ast.Node) string {
+	var b strings.Builder
+	printer.Fprint(&b, fset, node)
+	return b.String()
+}
@@ -0,0 +1,106 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseArities(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []int
+		wantErr bool
+	}{
+		{in: "", want: nil},
+		{in: "9", want: []int{9}},
+		{in: "9,12-14", want: []int{9, 12, 13, 14}},
+		{in: "0", wantErr: true},
+		{in: "5-3", wantErr: true},
+		{in: "x", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseArities(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseArities(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseArities(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseSignature(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    signature
+		wantErr bool
+	}{{
+		in: "ECancel[T any](T, func())",
+		want: signature{
+			Name:       "ECancel",
+			TypeParams: "T any",
+			Params:     []string{"v1", "v2"},
+			Types:      []string{"T", "func()"},
+		},
+	}, {
+		in: "EConn(conn *sql.Conn, n int)",
+		want: signature{
+			Name:   "EConn",
+			Params: []string{"conn", "n"},
+			Types:  []string{"*sql.Conn", "int"},
+		},
+	}, {
+		in:      "ENone()",
+		wantErr: true,
+	}, {
+		in:      "EResult(int) int",
+		wantErr: true,
+	}, {
+		in:      "EErr(err error)",
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		got, err := parseSignature(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseSignature(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseSignature(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	sig, err := parseSignature("ECancel[T any](T, func())")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := generate("foo", []string{"context"}, []signature{sig, aritySignature(2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(b)
+	for _, want := range []string{
+		"// Code generated by trygen. DO NOT EDIT.\n",
+		"package foo\n",
+		"\t\"context\"\n",
+		"\t\"github.com/dsnet/try\"\n",
+		"func ECancel[T any](v1 T, v2 func(), err error) (T, func()) {\n",
+		"func E2[T1, T2 any](v1 T1, v2 T2, err error) (T1, T2) {\n",
+		"// E2 returns v1 and v2 as is.\n",
+		"\ttry.ESkip(1, err)\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("generated source missing %q:\n%s", want, got)
+		}
+	}
+
+	if _, err := generate("foo", nil, []signature{sig, sig}); err == nil {
+		t.Errorf("generate with duplicate helpers succeeded, want error")
+	}
+}
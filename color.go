@@ -0,0 +1,55 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try
+
+import (
+	"os"
+	"runtime"
+	"strconv"
+)
+
+// ANSI escape sequences for terminal styling.
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiDim   = "\x1b[2m"
+	ansiRed   = "\x1b[31m"
+)
+
+// ColorFormatter returns a formatter for use with SetFormatter that renders
+// errors as by default, but with the file and line dimmed, the function bold,
+// and the error message in red, if f is a terminal.
+// If f is not a terminal or the NO_COLOR environment variable is set,
+// it returns a formatter that renders errors as by default.
+//
+//	func main() {
+//		try.SetFormatter(try.ColorFormatter(os.Stderr))
+//		defer try.F(log.Fatal)
+//		...
+//	}
+func ColorFormatter(f *os.File) func(err error, frame runtime.Frame) string {
+	if os.Getenv("NO_COLOR") != "" || !isTerminal(f) {
+		return formatError
+	}
+	return formatColor
+}
+
+// isTerminal reports whether f is a character device such as a terminal.
+func isTerminal(f *os.File) bool {
+	if f == nil {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// formatColor is like formatError, but styles the output for terminals.
+func formatColor(err error, frame runtime.Frame) string {
+	s := ansiDim + displayFile(frame) + ":" + strconv.Itoa(frame.Line) + ":" + ansiReset + " "
+	if frame.Function != "" {
+		s += ansiBold + lastSegment(frame.Function) + ansiReset + ": "
+	}
+	return s + ansiRed + err.Error() + ansiReset
+}
@@ -0,0 +1,38 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dsnet/try"
+)
+
+func TestColorFormatter(t *testing.T) {
+	e := recordError(func() {
+//line x.go:4
+		try.E(io.EOF)
+	})
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	try.SetFormatter(try.ColorFormatter(f))
+	defer try.SetFormatter(nil)
+	if got, want := try.TrimDebug(e.Error()), "x.go:4: try_test.TestColorFormatter.func1: EOF"; got != want {
+		t.Errorf("Error() for a regular file = %q, want %q", got, want)
+	}
+
+	try.SetFormatter(try.FormatColor)
+	want := "\x1b[2mx.go:4:\x1b[0m \x1b[1mtry_test.TestColorFormatter.func1\x1b[0m: \x1b[31mEOF\x1b[0m"
+	if got := try.TrimDebug(e.Error()); got != want {
+		t.Errorf("Error() for a terminal = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try
+
+import "context"
+
+// causeOf returns the cause of ctx if it is done, and err otherwise.
+func causeOf(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+	return err
+}
+
+// EC panics if err is non-nil.
+// If ctx is done, it panics with context.Cause(ctx) instead,
+// since the error of an operation interrupted by cancellation
+// is usually less informative than the cause of the cancellation.
+func EC(ctx context.Context, err error) {
+	if err != nil {
+		throw(causeOf(ctx, err))
+	}
+}
+
+// E1C returns a as is.
+// It panics if err is non-nil, preferring the cause of ctx as with EC.
+func E1C[A any](ctx context.Context, a A, err error) A {
+	if err != nil {
+		throw(causeOf(ctx, err))
+	}
+	return a
+}
+
+// E2C returns a and b as is.
+// It panics if err is non-nil, preferring the cause of ctx as with EC.
+func E2C[A, B any](ctx context.Context, a A, b B, err error) (A, B) {
+	if err != nil {
+		throw(causeOf(ctx, err))
+	}
+	return a, b
+}
+
+// E3C returns a, b, and c as is.
+// It panics if err is non-nil, preferring the cause of ctx as with EC.
+func E3C[A, B, C any](ctx context.Context, a A, b B, c C, err error) (A, B, C) {
+	if err != nil {
+		throw(causeOf(ctx, err))
+	}
+	return a, b, c
+}
+
+// E4C returns a, b, c, and d as is.
+// It panics if err is non-nil, preferring the cause of ctx as with EC.
+func E4C[A, B, C, D any](ctx context.Context, a A, b B, c C, d D, err error) (A, B, C, D) {
+	if err != nil {
+		throw(causeOf(ctx, err))
+	}
+	return a, b, c, d
+}
+
+// HandleContext recovers an error previously panicked with an E function and stores it into errptr.
+// If ctx is done, it stores context.Cause(ctx) instead,
+// so that cancellation is reported rather than whatever error it provoked.
+//
+//	func (s *Server) fetch(ctx context.Context) (err error) {
+//		defer try.HandleContext(ctx, &err)
+//		...
+//	}
+func HandleContext(ctx context.Context, errptr *error) {
+	r(recover(), func(w *Error) {
+		checkErrptr(errptr, w)
+		*errptr = causeOf(ctx, w.err)
+	})
+}
@@ -0,0 +1,61 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/dsnet/try"
+)
+
+func TestEC(t *testing.T) {
+	errShutdown := errors.New("shutdown")
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	var gotErr error
+	func() {
+		defer try.Handle(&gotErr)
+		try.EC(ctx, nil)
+		try.EC(ctx, io.EOF)
+	}()
+	if gotErr != io.EOF {
+		t.Errorf("recovered error: got %v, want %v", gotErr, io.EOF)
+	}
+
+	cancel(errShutdown)
+	gotErr = nil
+	func() {
+		defer try.Handle(&gotErr)
+		if v := try.E1C(ctx, 5, nil); v != 5 {
+			t.Errorf("E1C(ctx, 5, nil) = %v, want 5", v)
+		}
+		v := try.E1C(ctx, 5, io.EOF)
+		t.Errorf("E1C(ctx, 5, EOF) = %v, want panic", v)
+	}()
+	if gotErr != errShutdown {
+		t.Errorf("recovered error: got %v, want %v", gotErr, errShutdown)
+	}
+}
+
+func TestHandleContext(t *testing.T) {
+	errShutdown := errors.New("shutdown")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	run := func() (err error) {
+		defer try.HandleContext(ctx, &err)
+		try.E(io.ErrUnexpectedEOF)
+		return nil
+	}
+
+	if err := run(); err != io.ErrUnexpectedEOF {
+		t.Errorf("HandleContext: got %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	cancel(errShutdown)
+	if err := run(); err != errShutdown {
+		t.Errorf("HandleContext: got %v, want %v", err, errShutdown)
+	}
+}
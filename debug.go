@@ -0,0 +1,84 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+//go:build trydebug
+
+package try
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// debugMode reports whether the package is built with the trydebug build tag,
+// which enables diagnostics that are too expensive for normal use.
+const debugMode = true
+
+// goid returns the identifier of the current goroutine.
+func goid() uint64 {
+	// The trace begins with "goroutine 123 [running]:".
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = b[len("goroutine "):]
+	for i := range b {
+		if b[i] == ' ' {
+			b = b[:i]
+			break
+		}
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
+
+// crashHint returns an explanation to include in the message of e
+// when it is printed because it was not recovered and is crashing the program.
+// Otherwise, it returns the empty string.
+//
+// The explanation names the goroutine in which e occurred and, if it was
+// started by a go statement, the function, goroutine, and position of
+// that statement. It cannot name the nearest deferred handler instead,
+// since deferred calls are not observable until they run.
+func crashHint(e *Error) string {
+	// The runtime calls Error from runtime.preprintpanics
+	// when printing a panic value that is crashing the program.
+	frames := runtime.CallersFrames(callers(1))
+	for {
+		frame, more := frames.Next()
+		if frame.Function == "runtime.preprintpanics" {
+			break
+		}
+		if !more {
+			return ""
+		}
+	}
+
+	hint := "\ntry: E called on goroutine " + strconv.FormatUint(e.goid, 10) + " with no try handler"
+
+	// The trace of a goroutine ends with the function and position
+	// of the go statement that created it, if any:
+	//
+	//	created by main.main in goroutine 1
+	//		/path/to/main.go:34 +0x2d
+	buf := make([]byte, 64<<10)
+	trace := string(buf[:runtime.Stack(buf, false)])
+	i := strings.LastIndex(trace, "\ncreated by ")
+	if i < 0 {
+		return hint
+	}
+	lines := strings.SplitN(trace[i+len("\ncreated by "):], "\n", 3)
+	if len(lines) < 2 {
+		return hint
+	}
+	creator, pos := lines[0], strings.TrimSpace(lines[1])
+	if j := strings.LastIndex(pos, " +0x"); j >= 0 {
+		pos = pos[:j]
+	}
+	if j := strings.LastIndex(creator, " in goroutine "); j >= 0 {
+		hint += "; it was started by a go statement in " + creator[:j] + " on goroutine " + creator[j+len(" in goroutine "):] + " at " + pos
+	} else {
+		hint += "; it was started by a go statement in " + creator + " at " + pos
+	}
+	return hint + ", whose handlers cannot recover panics from other goroutines"
+}
@@ -0,0 +1,62 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+//go:build trydebug
+
+// The tests in this file are run with:
+//
+//	go test -tags trydebug ./...
+
+package try_test
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"testing"
+
+	"github.com/dsnet/try"
+)
+
+func TestDebugGoroutineID(t *testing.T) {
+	errc := make(chan error, 1)
+	go func() {
+		defer try.HandleChan(errc)
+//line x.go:4
+		try.E(io.EOF)
+	}()
+	err := <-errc
+	if want := `^x\.go:4: try_test\.TestDebugGoroutineID\.func1: EOF \[goroutine [1-9][0-9]*\]$`; !regexp.MustCompile(want).MatchString(err.Error()) {
+		t.Errorf("Error() = %q, want match of %q", err.Error(), want)
+	}
+}
+
+func TestDebugCrashHint(t *testing.T) {
+	if os.Getenv("TRY_TEST_CRASH") == "1" {
+		done := make(chan struct{})
+		go func() {
+//line x.go:4
+			try.E(io.EOF)
+		}()
+		<-done
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestDebugCrashHint$")
+	cmd.Env = append(os.Environ(), "TRY_TEST_CRASH=1")
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("crashing test succeeded unexpectedly")
+	}
+	want := `panic: x\.go:4: try_test\.TestDebugCrashHint\.func1: EOF \[goroutine (\d+)\]\n\t?` +
+		`try: E called on goroutine (\d+) with no try handler; it was started by a go statement in github\.com/dsnet/try_test\.TestDebugCrashHint on goroutine \d+ at \S+:\d+, ` +
+		`whose handlers cannot recover panics from other goroutines`
+	m := regexp.MustCompile(want).FindSubmatch(out)
+	if m == nil {
+		t.Fatalf("crash output:\n%s\nwant match of %q", out, want)
+	}
+	if string(m[1]) != string(m[2]) {
+		t.Errorf("goroutine ids differ: %s and %s", m[1], m[2])
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// These are variables so that they can be replaced in tests.
+var (
+	stderr io.Writer = os.Stderr
+	exit             = os.Exit
+)
+
+// exitCode returns the exit status for err.
+// It honors an ExitCode method on any error in err's tree,
+// such as that of exec.ExitError, provided that it reports a failure.
+func exitCode(err error) int {
+	var ec interface{ ExitCode() int }
+	if errors.As(err, &ec) && ec.ExitCode() > 0 {
+		return ec.ExitCode()
+	}
+	return 1
+}
+
+// HandleExit recovers an error previously panicked with an E function,
+// prints it to stderr with the file and line in which it occurred,
+// and exits the program with a non-zero status.
+// The status is 1 unless the error has an ExitCode method
+// that reports a non-zero status.
+// It is intended for use in main functions.
+//
+//	func main() {
+//		defer try.HandleExit()
+//		...
+//	}
+func HandleExit() {
+	r(recover(), func(w *Error) {
+		fmt.Fprintln(stderr, w)
+		exit(exitCode(w.err))
+	})
+}
+
+// Main runs fn and exits the program with a non-zero status if it fails.
+// An error returned by fn or panicked with an E function is printed to stderr,
+// where a panicked error includes the file and line in which it occurred.
+// The exit status is determined as with HandleExit.
+//
+//	func main() {
+//		try.Main(func() error {
+//			b := try.E1(os.ReadFile(...))
+//			...
+//			return nil
+//		})
+//	}
+func Main(fn func() error) {
+	defer HandleExit()
+	if err := fn(); err != nil {
+		fmt.Fprintln(stderr, err)
+		exit(exitCode(err))
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/dsnet/try"
+)
+
+type exitError struct{ code int }
+
+func (e exitError) Error() string { return fmt.Sprintf("exit status %d", e.code) }
+func (e exitError) ExitCode() int { return e.code }
+
+func TestHandleExit(t *testing.T) {
+	tests := []struct {
+		in         error
+		wantOutput string
+		wantCode   int
+	}{
+		{in: nil, wantCode: -1},
+		{in: io.EOF, wantOutput: "x.go:4: try_test.TestHandleExit.func2: EOF\n", wantCode: 1},
+		{in: exitError{3}, wantOutput: "x.go:4: try_test.TestHandleExit.func2: exit status 3\n", wantCode: 3},
+		{in: exitError{-1}, wantOutput: "x.go:4: try_test.TestHandleExit.func2: exit status -1\n", wantCode: 1},
+	}
+	for _, tt := range tests {
+		buf := new(strings.Builder)
+		gotCode := -1
+		restore := try.SetExit(func(code int) { gotCode = code }, buf)
+		func() {
+			defer try.HandleExit()
+//line /full/path/to/x.go:4
+			try.E(tt.in)
+		}()
+		restore()
+		if try.TrimDebug(buf.String()) != tt.wantOutput {
+			t.Errorf("HandleExit(%v) printed %q, want %q", tt.in, buf.String(), tt.wantOutput)
+		}
+		if gotCode != tt.wantCode {
+			t.Errorf("HandleExit(%v) exited with %d, want %d", tt.in, gotCode, tt.wantCode)
+		}
+	}
+}
+
+func TestTryMain(t *testing.T) {
+	tests := []struct {
+		fn         func() error
+		wantOutput string
+		wantCode   int
+	}{{
+		fn:       func() error { return nil },
+		wantCode: -1,
+	}, {
+		fn:         func() error { return exitError{2} },
+		wantOutput: "exit status 2\n",
+		wantCode:   2,
+	}, {
+		fn: func() error {
+//line /full/path/to/x.go:4
+			try.E(io.EOF)
+			return nil
+		},
+		wantOutput: "x.go:4: try_test.TestTryMain.func3: EOF\n",
+		wantCode:   1,
+	}}
+	for _, tt := range tests {
+		buf := new(strings.Builder)
+		gotCode := -1
+		restore := try.SetExit(func(code int) { gotCode = code }, buf)
+		try.Main(tt.fn)
+		restore()
+		if try.TrimDebug(buf.String()) != tt.wantOutput {
+			t.Errorf("Main printed %q, want %q", buf.String(), tt.wantOutput)
+		}
+		if gotCode != tt.wantCode {
+			t.Errorf("Main exited with %d, want %d", gotCode, tt.wantCode)
+		}
+	}
+}
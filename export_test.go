@@ -0,0 +1,38 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try
+
+import (
+	"io"
+	"regexp"
+)
+
+// SetExit replaces the exit function and standard error output
+// and returns a function that restores the originals.
+func SetExit(fn func(int), w io.Writer) (restore func()) {
+	oldExit, oldStderr := exit, stderr
+	exit, stderr = fn, w
+	return func() { exit, stderr = oldExit, oldStderr }
+}
+
+// RelativeFile is relativeFile for testing.
+var RelativeFile = relativeFile
+
+// SourceLine is sourceLine for testing.
+var SourceLine = sourceLine
+
+// FormatColor is formatColor for testing.
+var FormatColor = formatColor
+
+var debugSuffix = regexp.MustCompile(` \[goroutine \d+\]`)
+
+// TrimDebug removes the goroutine IDs that the trydebug build tag
+// appends to error messages, so that tests pass in both build modes.
+func TrimDebug(s string) string {
+	if !debugMode {
+		return s
+	}
+	return debugSuffix.ReplaceAllString(s, "")
+}
@@ -0,0 +1,223 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var redactor atomic.Pointer[func(string) string]
+
+// SetRedactor sets a function that is applied to the text of errors
+// before it is rendered by Error, the %v and %+v verbs, MarshalJSON, LogValue,
+// HandleLog, and HandleSlog, so that secrets that leak into error messages,
+// such as credentials in connection strings, can be scrubbed before being logged.
+// It does not alter the underlying errors, which handlers store as is.
+// It must be safe for concurrent use.
+// Passing nil removes any previously set function.
+//
+//	var credentials = regexp.MustCompile(`://[^@/]*@`)
+//	try.SetRedactor(func(s string) string {
+//		return credentials.ReplaceAllString(s, "://REDACTED@")
+//	})
+func SetRedactor(fn func(string) string) {
+	if fn == nil {
+		redactor.Store(nil)
+	} else {
+		redactor.Store(&fn)
+	}
+}
+
+// redact applies the function set by SetRedactor to s, if any.
+func redact(s string) string {
+	if fn := redactor.Load(); fn != nil {
+		return (*fn)(s)
+	}
+	return s
+}
+
+var sourceLines atomic.Bool
+
+// SetSourceLines sets whether the %+v verb of Error prints the line of
+// source code in which the error occurred, if the source file is available:
+//
+//	unexpected EOF
+//	example.com/parser.(*Lexer).next
+//		/src/parser/lex.go:87
+//		87 | try.E(l.r.UnreadRune())
+//
+// Reading source files is expensive, so this is intended for
+// debugging and tests rather than production logging.
+func SetSourceLines(enable bool) {
+	sourceLines.Store(enable)
+}
+
+// sourceLine returns the given 1-based line of file without surrounding whitespace.
+// It reports false if the file cannot be read or has no such line.
+func sourceLine(file string, line int) (string, bool) {
+	b, err := os.ReadFile(file)
+	if err != nil || line < 1 {
+		return "", false
+	}
+	lines := strings.Split(string(b), "\n")
+	if line > len(lines) {
+		return "", false
+	}
+	return strings.TrimSpace(lines[line-1]), true
+}
+
+var formatter atomic.Pointer[func(err error, frame runtime.Frame) string]
+
+// SetFormatter sets a function that renders the message of an Error
+// from the underlying error and the frame in which it occurred.
+// It affects Error and the %s, %q, and %v verbs, but not %+v.
+// It must be safe for concurrent use.
+// Passing nil restores the default rendering.
+//
+//	try.SetFormatter(func(err error, frame runtime.Frame) string {
+//		return fmt.Sprintf("%v (%s)", err, frame.Function)
+//	})
+func SetFormatter(fn func(err error, frame runtime.Frame) string) {
+	if fn == nil {
+		formatter.Store(nil)
+	} else {
+		formatter.Store(&fn)
+	}
+}
+
+// modulePaths returns the paths of the modules in the build.
+var modulePaths = sync.OnceValue(func() []string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+	paths := []string{bi.Main.Path}
+	for _, dep := range bi.Deps {
+		paths = append(paths, dep.Path)
+	}
+	return paths
+})
+
+var workdir atomic.Pointer[string]
+
+// SetWorkdirPaths sets whether errors render file paths relative to
+// the current working directory for files within it
+// (e.g., "cmd/tool/main.go:42"), which editors and terminals can open directly
+// when a program is run from the root of its source tree.
+// Files outside the working directory are rendered as usual.
+// The working directory is determined when the mode is enabled.
+// It is intended for command-line tools; servers usually have
+// working directories unrelated to their source.
+func SetWorkdirPaths(enable bool) {
+	wd, err := os.Getwd()
+	if !enable || err != nil {
+		workdir.Store(nil)
+		return
+	}
+	prefix := strings.TrimSuffix(filepath.ToSlash(wd), "/") + "/"
+	workdir.Store(&prefix)
+}
+
+// displayFile returns the file of frame as rendered in error messages.
+func displayFile(frame runtime.Frame) string {
+	if prefix := workdir.Load(); prefix != nil && strings.HasPrefix(frame.File, *prefix) {
+		return frame.File[len(*prefix):]
+	}
+	return relativeFile(frame.Function, frame.File, modulePaths())
+}
+
+// relativeFile returns the file relative to the root of the module
+// containing the package of function (e.g., "internal/parser/lex.go"),
+// where modules is the list of module paths to consider.
+// If the module cannot be determined, it returns only the last path segment.
+func relativeFile(function, file string, modules []string) string {
+	base := lastSegment(file)
+
+	// The package path ends at the first dot after the last slash,
+	// since the linker escapes dots in the last element of package paths.
+	pkg := function
+	slash := strings.LastIndexByte(pkg, '/') + len("/")
+	if i := strings.IndexByte(pkg[slash:], '.'); i >= 0 {
+		pkg = pkg[:slash+i]
+	}
+
+	// Use the longest module path that contains the package.
+	n := -1
+	for _, mod := range modules {
+		if mod != "" && len(mod) > n && (pkg == mod || strings.HasPrefix(pkg, mod+"/")) {
+			n = len(mod)
+		}
+	}
+	if n < 0 || n == len(pkg) {
+		return base
+	}
+	return pkg[n+len("/"):] + "/" + base
+}
+
+// Format implements fmt.Formatter.
+// The %s and %v verbs print the same as Error, and %q prints it quoted.
+// The %+v verb prints the underlying error formatted with %+v and its ID,
+// followed by each recorded frame as a function name on one line
+// and an indented file and line on the next.
+// See SetStackDepth for recording more than one frame.
+// If SetSourceLines is enabled, the first frame is followed by
+// the line of source code in which the error occurred.
+func (e *Error) Format(s fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && s.Flag('+'):
+		io.WriteString(s, redact(fmt.Sprintf("%+v", e.err))+" (id "+e.ID()+")")
+		for i, frame := range e.Stack() {
+			io.WriteString(s, "\n"+frame.Function+"\n\t"+frame.File+":"+strconv.Itoa(frame.Line))
+			if i == 0 && sourceLines.Load() {
+				if line, ok := sourceLine(frame.File, frame.Line); ok {
+					io.WriteString(s, "\n\t"+strconv.Itoa(frame.Line)+" | "+line)
+				}
+			}
+		}
+	case verb == 'v' || verb == 's':
+		io.WriteString(s, e.Error())
+	case verb == 'q':
+		io.WriteString(s, strconv.Quote(e.Error()))
+	default:
+		fmt.Fprintf(s, "%%!%c(*try.Error=%s)", verb, e.Error())
+	}
+}
+
+// ID returns a short identifier derived from the function and line
+// in which the error occurred and the classification of the error,
+// which is the Go type of the error as reported by HandleMetrics.
+// It is stable across runs and builds of the same source code,
+// so that it can be used to correlate reports of an error with its call site.
+func (e *Error) ID() string {
+	frame := e.Frame()
+	h := fnv.New32a()
+	io.WriteString(h, frame.Function+":"+strconv.Itoa(frame.Line)+":"+classify(e.err))
+	return fmt.Sprintf("%08x", h.Sum32())
+}
+
+// MarshalJSON implements json.Marshaler.
+// It encodes the message of the underlying error and the frame in which it occurred
+// as a JSON object with "msg", "file", "line", and "func" members,
+// where the file is rendered as in Error and the function is fully qualified.
+func (e *Error) MarshalJSON() ([]byte, error) {
+	frame := e.Frame()
+	return json.Marshal(struct {
+		Msg  string `json:"msg"`
+		File string `json:"file"`
+		Line int    `json:"line"`
+		Func string `json:"func"`
+	}{redact(e.err.Error()), displayFile(frame), frame.Line, frame.Function})
+}
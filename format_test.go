@@ -0,0 +1,255 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/dsnet/try"
+)
+
+// recordError returns the *try.Error panicked by fn.
+func recordError(fn func()) (e *try.Error) {
+	defer try.F(func(args ...any) { e = args[0].(*try.Error) })
+	fn()
+	return nil
+}
+
+func TestFormat(t *testing.T) {
+	try.SetStackDepth(2)
+	defer try.SetStackDepth(1)
+	e := recordError(func() {
+//line /full/path/to/x.go:4
+		try.E(io.EOF)
+	})
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%v", "x.go:4: try_test.TestFormat.func1: EOF"},
+		{"%s", "x.go:4: try_test.TestFormat.func1: EOF"},
+		{"%q", `"x.go:4: try_test.TestFormat.func1: EOF"`},
+		{"%d", "%!d(*try.Error=x.go:4: try_test.TestFormat.func1: EOF)"},
+		{"%+v", `^EOF \(id [0-9a-f]{8}\)\n` +
+			`github.com/dsnet/try_test\.TestFormat\.func1\n\t/full/path/to/x\.go:4\n` +
+			`github.com/dsnet/try_test\.recordError\n\t.*format_test\.go:\d+$`},
+	}
+	for _, tt := range tests {
+		got := try.TrimDebug(fmt.Sprintf(tt.format, e))
+		if tt.format == "%+v" {
+			if !regexp.MustCompile(tt.want).MatchString(got) {
+				t.Errorf("Sprintf(%q) = %q, want match of %q", tt.format, got, tt.want)
+			}
+		} else if got != tt.want {
+			t.Errorf("Sprintf(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestRelativeFile(t *testing.T) {
+	modules := []string{"example.com/mod", "example.com/mod/sub", "gopkg.in/yaml.v3"}
+	tests := []struct {
+		function, file string
+		want           string
+	}{
+		{"example.com/mod.F", "/src/mod/x.go", "x.go"},
+		{"example.com/mod/internal/parser.(*Lexer).next", "/src/mod/internal/parser/lex.go", "internal/parser/lex.go"},
+		{"example.com/mod/sub/pkg.F.func1", "/src/sub/pkg/x.go", "pkg/x.go"},
+		{"example.com/mod_test.F", "/src/mod/x_test.go", "x_test.go"},
+		{"example.com/modular.F", "/src/modular/x.go", "x.go"},
+		{"gopkg.in/yaml%2ev3.Unmarshal", "/go/pkg/mod/gopkg.in/yaml.v3@v3.0.1/yaml.go", "yaml.go"},
+		{"main.main", "/src/main.go", "main.go"},
+		{"", "/src/x.go", "x.go"},
+	}
+	for _, tt := range tests {
+		if got := try.RelativeFile(tt.function, tt.file, modules); got != tt.want {
+			t.Errorf("relativeFile(%q, %q) = %q, want %q", tt.function, tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestSetFormatter(t *testing.T) {
+	e := recordError(func() {
+//line /full/path/to/x.go:4
+		try.E(io.EOF)
+	})
+
+	try.SetFormatter(func(err error, frame runtime.Frame) string {
+		return fmt.Sprintf("%v@%s:%d", err, filepath.Base(frame.File), frame.Line)
+	})
+	defer try.SetFormatter(nil)
+	if got, want := try.TrimDebug(e.Error()), "EOF@x.go:4"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if got, want := try.TrimDebug(fmt.Sprintf("%v", e)), "EOF@x.go:4"; got != want {
+		t.Errorf("Sprintf(%%v) = %q, want %q", got, want)
+	}
+
+	try.SetFormatter(nil)
+	if got, want := try.TrimDebug(e.Error()), "x.go:4: try_test.TestSetFormatter.func1: EOF"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	e := recordError(func() {
+//line /full/path/to/x.go:4
+		try.E(io.EOF)
+	})
+	b, err := json.Marshal(map[string]any{"error": e})
+	if err != nil {
+		t.Fatalf("json.Marshal error: %v", err)
+	}
+	const want = `{"error":{"msg":"EOF","file":"x.go","line":4,"func":"github.com/dsnet/try_test.TestMarshalJSON.func1"}}`
+	if string(b) != want {
+		t.Errorf("json.Marshal = %s, want %s", b, want)
+	}
+}
+
+func TestSetWorkdirPaths(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := recordError(func() {
+//line x.go:4
+		try.E(io.EOF)
+	})
+	// Relative line directives are resolved against the working directory.
+	if !strings.HasPrefix(filepath.ToSlash(e.Frame().File), filepath.ToSlash(wd)+"/") {
+		t.Skipf("frame file %q is not within %q", e.Frame().File, wd)
+	}
+
+	// Render paths relative to the parent of the working directory.
+	parent := filepath.Dir(wd)
+	if err := os.Chdir(parent); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	try.SetWorkdirPaths(true)
+	defer try.SetWorkdirPaths(false)
+	want := filepath.Base(wd) + "/x.go:4: try_test.TestSetWorkdirPaths.func1: EOF"
+	if got := try.TrimDebug(e.Error()); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+
+	try.SetWorkdirPaths(false)
+	if got, want := try.TrimDebug(e.Error()), "x.go:4: try_test.TestSetWorkdirPaths.func1: EOF"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestSetSourceLines(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "x.go")
+	if err := os.WriteFile(file, []byte("package x\n\n\ttry.E(err)\r\n"), 0664); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		line   int
+		want   string
+		wantOK bool
+	}{
+		{line: 0},
+		{line: 1, want: "package x", wantOK: true},
+		{line: 3, want: "try.E(err)", wantOK: true},
+		{line: 5},
+	} {
+		if got, ok := try.SourceLine(file, tt.line); got != tt.want || ok != tt.wantOK {
+			t.Errorf("sourceLine(%d) = (%q, %v), want (%q, %v)", tt.line, got, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	// Unavailable source files are silently omitted.
+	try.SetSourceLines(true)
+	defer try.SetSourceLines(false)
+	e := recordError(func() {
+//line /full/path/to/x.go:4
+		try.E(io.EOF)
+	})
+	if got := fmt.Sprintf("%+v", e); strings.Contains(got, " | ") {
+		t.Errorf("Sprintf(%%+v) = %q, want no source line", got)
+	}
+
+	// Attribute the error to the first line of this file.
+	e = recordError(func() {
+//line format_test.go:1
+		try.E(io.EOF)
+	})
+	if got, want := fmt.Sprintf("%+v", e), "\n\t1 | // Copyright 2022, Joe Tsai. All rights reserved."; !strings.HasSuffix(got, want) {
+		t.Errorf("Sprintf(%%+v) = %q, want suffix %q", got, want)
+	}
+}
+
+func TestID(t *testing.T) {
+	record := func(err error) *try.Error {
+		return recordError(func() {
+//line x.go:4
+			try.E(err)
+		})
+	}
+	a1, a2 := record(io.EOF), record(io.ErrUnexpectedEOF)
+	b := record(&os.PathError{Op: "open", Path: "x", Err: os.ErrNotExist})
+	c := recordError(func() {
+//line x.go:8
+		try.E(io.EOF)
+	})
+	if a1.ID() != a2.ID() {
+		t.Errorf("IDs differ for the same site and class: %s and %s", a1.ID(), a2.ID())
+	}
+	if a1.ID() == c.ID() {
+		t.Errorf("IDs equal for different sites: %s", a1.ID())
+	}
+	if len(a1.ID()) != 8 {
+		t.Errorf("len(ID()) = %d, want 8", len(a1.ID()))
+	}
+	if got := fmt.Sprintf("%+v", b); !strings.Contains(got, "(id "+b.ID()+")") {
+		t.Errorf("Sprintf(%%+v) = %q, want it to contain the ID %s", got, b.ID())
+	}
+}
+
+func TestSetRedactor(t *testing.T) {
+	try.SetRedactor(func(s string) string { return strings.ReplaceAll(s, "hunter2", "REDACTED") })
+	defer try.SetRedactor(nil)
+
+	secret := errors.New("dial postgres://admin:hunter2@db: refused")
+	e := recordError(func() {
+//line x.go:4
+		try.E(secret)
+	})
+	if got, want := try.TrimDebug(e.Error()), "x.go:4: try_test.TestSetRedactor.func2: dial postgres://admin:REDACTED@db: refused"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	for _, format := range []string{"%v", "%+v"} {
+		if got := fmt.Sprintf(format, e); strings.Contains(got, "hunter2") {
+			t.Errorf("Sprintf(%q) = %q, want it redacted", format, got)
+		}
+	}
+	if b, _ := json.Marshal(e); strings.Contains(string(b), "hunter2") {
+		t.Errorf("json.Marshal = %s, want it redacted", b)
+	}
+
+	buf := new(strings.Builder)
+	func() {
+		defer try.HandleLog(log.New(buf, "", 0), nil)
+		try.E(secret)
+	}()
+	if got, want := buf.String(), "dial postgres://admin:REDACTED@db: refused\n"; got != want {
+		t.Errorf("HandleLog logged %q, want %q", got, want)
+	}
+	if e.Unwrap() != secret {
+		t.Errorf("Unwrap() = %v, want the unredacted error", e.Unwrap())
+	}
+}
@@ -1,3 +1,3 @@
 module github.com/dsnet/try
 
-go 1.18
+go 1.21
@@ -0,0 +1,117 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try
+
+import (
+	"log"
+	"sync/atomic"
+)
+
+var goSink atomic.Pointer[func(err error)]
+
+// SetGoSink sets the function that receives errors recovered by Go.
+// It must be safe for concurrent use.
+// Passing nil restores the default, which logs the error with log.Print.
+func SetGoSink(fn func(err error)) {
+	if fn == nil {
+		goSink.Store(nil)
+	} else {
+		goSink.Store(&fn)
+	}
+}
+
+// sinkError passes err to the function set by SetGoSink.
+func sinkError(err error) {
+	if fn := goSink.Load(); fn != nil {
+		(*fn)(err)
+	} else {
+		log.Print(err)
+	}
+}
+
+// Go runs fn in a new goroutine that recovers from any panic as with HandleAny.
+// The recovered error is passed to the function set by SetGoSink
+// instead of crashing the program.
+//
+//	try.Go(func() {
+//		b := try.E1(os.ReadFile(path))
+//		...
+//	})
+func Go(fn func()) {
+	GoHandle(fn, sinkError)
+}
+
+// GoHandle runs fn in a new goroutine that recovers from any panic as with HandleAny
+// and calls h with the recovered error, if any.
+//
+//	try.GoHandle(func() {
+//		try.E(process(task))
+//	}, func(err error) {
+//		retries <- task
+//	})
+func GoHandle(fn func(), h func(err error)) {
+	go func() {
+		var err error
+		defer func() {
+			if err != nil {
+				h(err)
+			}
+		}()
+		defer HandleAny(&err)
+		fn()
+	}()
+}
+
+// Async runs fn in a new goroutine that recovers from any panic as with HandleAny
+// and returns a function that waits for fn to return and reports
+// its result or the recovered error. The returned function may be called
+// any number of times and reports the same result each time.
+//
+//	getConfig := try.Async(func() *Config { return try.E1(loadConfig()) })
+//	...
+//	cfg, err := getConfig()
+func Async[T any](fn func() T) func() (T, error) {
+	var v T
+	var err error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer HandleAny(&err)
+		v = fn()
+	}()
+	return func() (T, error) {
+		<-done
+		return v, err
+	}
+}
+
+// Wrap returns a function that calls fn and returns the error
+// panicked by an E function in fn, if any, as with Handle.
+// It adapts fn for APIs that expect a func() error,
+// such as golang.org/x/sync/errgroup.Group.Go.
+//
+//	g.Go(try.Wrap(func() {
+//		try.E(upload(ctx, file))
+//	}))
+func Wrap(fn func()) func() error {
+	return func() (err error) {
+		defer Handle(&err)
+		fn()
+		return nil
+	}
+}
+
+// Wrap1 is like Wrap, but the returned function calls fn with a.
+//
+//	for _, file := range files {
+//		g.Go(try.Wrap1(upload, file))
+//	}
+func Wrap1[A any](fn func(A), a A) func() error {
+	return func() (err error) {
+		defer Handle(&err)
+		fn(a)
+		return nil
+	}
+}
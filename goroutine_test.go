@@ -0,0 +1,95 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/dsnet/try"
+)
+
+func TestGo(t *testing.T) {
+	errc := make(chan error, 1)
+	try.SetGoSink(func(err error) { errc <- err })
+	defer try.SetGoSink(nil)
+
+	try.Go(func() {
+//line x.go:4
+		try.E(io.EOF)
+	})
+	if err := <-errc; !errors.Is(err, io.EOF) || !errors.Is(err, try.ErrRecovered) {
+		t.Errorf("Go sank %v, want a recovered %v", err, io.EOF)
+	}
+
+	try.Go(func() { panic("boom") })
+	if err := <-errc; err == nil || err.Error() != "panic: boom" {
+		t.Errorf("Go sank %v, want panic: boom", err)
+	}
+
+	done := make(chan struct{})
+	try.Go(func() { close(done) })
+	<-done
+	select {
+	case err := <-errc:
+		t.Errorf("Go sank %v for a successful function", err)
+	default:
+	}
+}
+
+func TestGoHandle(t *testing.T) {
+	errc := make(chan error, 1)
+	try.GoHandle(func() {
+		try.E(io.EOF)
+	}, func(err error) { errc <- err })
+	if err := <-errc; !errors.Is(err, io.EOF) {
+		t.Errorf("GoHandle handled %v, want %v", err, io.EOF)
+	}
+
+	done := make(chan struct{})
+	try.GoHandle(func() { close(done) }, func(err error) { errc <- err })
+	<-done
+	select {
+	case err := <-errc:
+		t.Errorf("GoHandle handled %v for a successful function", err)
+	default:
+	}
+}
+
+func TestAsync(t *testing.T) {
+	get := try.Async(func() int { return 5 })
+	for i := 0; i < 2; i++ {
+		if v, err := get(); v != 5 || err != nil {
+			t.Errorf("Async result = (%v, %v), want (5, nil)", v, err)
+		}
+	}
+
+	get = try.Async(func() int {
+		try.E(io.EOF)
+		return 5
+	})
+	if v, err := get(); v != 0 || !errors.Is(err, io.EOF) {
+		t.Errorf("Async result = (%v, %v), want (0, %v)", v, err, io.EOF)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	if err := try.Wrap(func() {})(); err != nil {
+		t.Errorf("Wrap(success)() = %v, want nil", err)
+	}
+	if err := try.Wrap(func() { try.E(io.EOF) })(); err != io.EOF {
+		t.Errorf("Wrap(failure)() = %v, want %v", err, io.EOF)
+	}
+
+	var got string
+	fn := try.Wrap1(func(s string) {
+		got = s
+		try.E(io.EOF)
+	}, "hello")
+	if err := fn(); err != io.EOF || got != "hello" {
+		t.Errorf("Wrap1(fn, hello)() = %v with argument %q, want %v with argument hello", err, got, io.EOF)
+	}
+}
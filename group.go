@@ -0,0 +1,291 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Group is a collection of goroutines whose functions may use the E functions.
+// It is similar to golang.org/x/sync/errgroup.Group, but the functions
+// report failure by panicking rather than returning an error.
+// A zero Group is ready for use and must not be copied after first use.
+//
+//	var g try.Group
+//	for _, url := range urls {
+//		url := url
+//		g.Go(func() {
+//			resp := try.E1(http.Get(url))
+//			...
+//		})
+//	}
+//	try.E(g.Wait())
+type Group struct {
+	cancel context.CancelCauseFunc
+
+	wg   sync.WaitGroup
+	once sync.Once
+	err  error
+
+	mu   sync.Mutex
+	done chan struct{}
+}
+
+// GroupContext returns a new Group and a context derived from ctx.
+// The context is canceled, with the error as its cause, the first time
+// a function started by Go fails or the first time Wait returns, whichever occurs first.
+//
+//	g, ctx := try.GroupContext(ctx)
+//	for _, url := range urls {
+//		url := url
+//		g.Go(func() {
+//			req := try.E1(http.NewRequestWithContext(ctx, "GET", url, nil))
+//			...
+//		})
+//	}
+//	try.E(g.Wait())
+func GroupContext(ctx context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &Group{cancel: cancel}, ctx
+}
+
+// Go runs fn in a new goroutine that recovers from any panic as with HandleAny.
+// The first recovered error is returned by Wait.
+func (g *Group) Go(fn func()) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		var err error
+		defer func() {
+			if err != nil {
+				g.once.Do(func() {
+					g.err = err
+					close(g.doneChan())
+					if g.cancel != nil {
+						g.cancel(err)
+					}
+				})
+			}
+		}()
+		defer HandleAny(&err)
+		fn()
+	}()
+}
+
+// Done returns a channel that is closed the first time a function started by Go fails.
+// Functions that may block, such as those sending on a channel,
+// can select on it to stop early once the group has failed.
+func (g *Group) Done() <-chan struct{} {
+	return g.doneChan()
+}
+
+func (g *Group) doneChan() chan struct{} {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.done == nil {
+		g.done = make(chan struct{})
+	}
+	return g.done
+}
+
+// Wait blocks until all functions started by Go have returned
+// and then returns the first error recovered from them, if any.
+// An error panicked by an E function retains the frame in which it occurred.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	if g.cancel != nil {
+		g.cancel(g.err)
+	}
+	return g.err
+}
+
+// Parallel runs each of fns in its own goroutine that recovers from any panic
+// as with HandleAny and waits for all of them to return.
+// If any fail, it panics in the caller as with E, with the errors joined
+// as with errors.Join in the order of fns.
+//
+//	func load() (err error) {
+//		defer try.Handle(&err)
+//		try.Parallel(loadUsers, loadGroups, loadPermissions)
+//		...
+//	}
+func Parallel(fns ...func()) {
+	var wg sync.WaitGroup
+	errs := make([]error, len(fns))
+	for i, fn := range fns {
+		wg.Add(1)
+		go func(errptr *error, fn func()) {
+			defer wg.Done()
+			defer HandleAny(errptr)
+			fn()
+		}(&errs[i], fn)
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		throw(err)
+	}
+}
+
+// Pool is a bounded collection of goroutines whose functions may use the E functions.
+// Unlike Group, it reports all failures rather than only the first.
+type Pool struct {
+	sem chan struct{}
+	wg  sync.WaitGroup
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// NewPool returns a Pool that runs at most limit functions at a time.
+// If limit is not positive, the number of functions is not limited.
+//
+//	pool := try.NewPool(8)
+//	for _, path := range paths {
+//		path := path
+//		pool.Go(func() {
+//			b := try.E1(os.ReadFile(path))
+//			...
+//		})
+//	}
+//	try.E(pool.Wait())
+func NewPool(limit int) *Pool {
+	p := new(Pool)
+	if limit > 0 {
+		p.sem = make(chan struct{}, limit)
+	}
+	return p
+}
+
+// Go runs fn in a new goroutine that recovers from any panic as with HandleAny,
+// blocking until fewer than the limit of functions are running.
+func (p *Pool) Go(fn func()) {
+	if p.sem != nil {
+		p.sem <- struct{}{}
+	}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		var err error
+		defer func() {
+			if p.sem != nil {
+				<-p.sem
+			}
+			if err != nil {
+				p.mu.Lock()
+				p.errs = append(p.errs, err)
+				p.mu.Unlock()
+			}
+		}()
+		defer HandleAny(&err)
+		fn()
+	}()
+}
+
+// Wait blocks until all functions started by Go have returned
+// and then returns the errors recovered from them joined as with errors.Join
+// in the order they were recovered.
+func (p *Pool) Wait() error {
+	p.wg.Wait()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return errors.Join(p.errs...)
+}
+
+// WaitGroup is a collection of goroutines whose functions may use the E functions.
+// It is like sync.WaitGroup, but Wait reports the errors recovered from the functions.
+// A zero WaitGroup is ready for use and must not be copied after first use.
+//
+//	var wg try.WaitGroup
+//	for _, path := range paths {
+//		path := path
+//		wg.Go(func() { try.E(os.Remove(path)) })
+//	}
+//	try.E(wg.Wait())
+type WaitGroup struct {
+	pool Pool // without a limit
+}
+
+// Go runs fn in a new goroutine that recovers from any panic as with HandleAny.
+func (wg *WaitGroup) Go(fn func()) {
+	wg.pool.Go(fn)
+}
+
+// Wait blocks until all functions started by Go have returned
+// and then returns the errors recovered from them joined as with errors.Join
+// in the order they were recovered.
+func (wg *WaitGroup) Wait() error {
+	return wg.pool.Wait()
+}
+
+// Race runs each of fns concurrently in goroutines that recover from any panic
+// as with HandleAny, and returns the value of the first to succeed.
+// Once a function succeeds, the context passed to the others is canceled.
+// If all functions fail, it returns their errors joined as with errors.Join
+// in the order of fns. Race does not wait for canceled functions to return.
+//
+//	v, err := try.Race(ctx, queryPrimary, queryReplica)
+func Race[T any](ctx context.Context, fns ...func(context.Context) T) (T, error) {
+	var zero T
+	if len(fns) == 0 {
+		return zero, errors.New("try: Race called without functions")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		i   int
+		v   T
+		err error
+	}
+	results := make(chan result, len(fns))
+	for i, fn := range fns {
+		go func(i int, fn func(context.Context) T) {
+			r := result{i: i}
+			defer func() { results <- r }()
+			defer HandleAny(&r.err)
+			r.v = fn(ctx)
+		}(i, fn)
+	}
+
+	errs := make([]error, len(fns))
+	for range fns {
+		r := <-results
+		if r.err == nil {
+			return r.v, nil
+		}
+		errs[r.i] = r.err
+	}
+	return zero, errors.Join(errs...)
+}
+
+// MapConcurrent calls f with each of items in goroutines that recover from
+// any panic as with HandleAny, running at most limit calls at a time,
+// and returns the results in the order of items.
+// If limit is not positive, the number of calls is not limited.
+// If any calls fail, it panics in the caller as with E, with the errors joined
+// as with errors.Join in the order of items.
+//
+//	users := try.MapConcurrent(ids, 16, func(id string) *User {
+//		return try.E1(db.LookupUser(ctx, id))
+//	})
+func MapConcurrent[T, U any](items []T, limit int, f func(T) U) []U {
+	pool := NewPool(limit)
+	results := make([]U, len(items))
+	errs := make([]error, len(items))
+	for i := range items {
+		i := i
+		pool.Go(func() {
+			defer HandleAny(&errs[i])
+			results[i] = f(items[i])
+		})
+	}
+	pool.Wait()
+	if err := errors.Join(errs...); err != nil {
+		throw(err)
+	}
+	return results
+}
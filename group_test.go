@@ -0,0 +1,203 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/dsnet/try"
+)
+
+func TestGroup(t *testing.T) {
+	var g try.Group
+	var n atomic.Int32
+	for i := 0; i < 4; i++ {
+		g.Go(func() { n.Add(1) })
+	}
+	if err := g.Wait(); err != nil || n.Load() != 4 {
+		t.Errorf("Wait() = %v after %d calls, want nil after 4 calls", err, n.Load())
+	}
+
+	g = try.Group{}
+	g.Go(func() {
+//line x.go:4
+		try.E(io.EOF)
+	})
+	g.Go(func() {})
+	err := g.Wait()
+	if !errors.Is(err, io.EOF) || !errors.Is(err, try.ErrRecovered) {
+		t.Errorf("Wait() = %v, want a recovered %v", err, io.EOF)
+	}
+
+	g = try.Group{}
+	for i := 0; i < 4; i++ {
+		g.Go(func() { panic("boom") })
+	}
+	if err := g.Wait(); err == nil || err.Error() != "panic: boom" {
+		t.Errorf("Wait() = %v, want panic: boom", err)
+	}
+}
+
+func TestGroupContext(t *testing.T) {
+	g, ctx := try.GroupContext(context.Background())
+	g.Go(func() {
+		<-ctx.Done()
+		try.E(ctx.Err())
+	})
+	g.Go(func() {
+		try.E(io.EOF)
+	})
+	err := g.Wait()
+	if !errors.Is(err, io.EOF) {
+		t.Errorf("Wait() = %v, want %v", err, io.EOF)
+	}
+	if cause := context.Cause(ctx); cause != err {
+		t.Errorf("context.Cause = %v, want %v", cause, err)
+	}
+
+	g, ctx = try.GroupContext(context.Background())
+	g.Go(func() {})
+	if err := g.Wait(); err != nil {
+		t.Errorf("Wait() = %v, want nil", err)
+	}
+	if ctx.Err() != context.Canceled {
+		t.Errorf("ctx.Err() = %v, want %v", ctx.Err(), context.Canceled)
+	}
+}
+
+func TestParallel(t *testing.T) {
+	var n atomic.Int32
+	err := func() (err error) {
+		defer try.Handle(&err)
+		try.Parallel(func() { n.Add(1) }, func() { n.Add(1) })
+		return nil
+	}()
+	if err != nil || n.Load() != 2 {
+		t.Errorf("Parallel = %v after %d calls, want nil after 2 calls", err, n.Load())
+	}
+
+	var frame runtime.Frame
+	err = func() (err error) {
+		defer try.HandleStack(&err, func(stack []runtime.Frame) { frame = stack[0] })
+//line x.go:4
+		try.Parallel(func() { n.Add(1) }, func() { try.E(io.EOF) }, func() { panic("boom") })
+		return nil
+	}()
+	if !errors.Is(err, io.EOF) || !strings.HasSuffix(err.Error(), "\npanic: boom") {
+		t.Errorf("Parallel = %q, want joined EOF and panic: boom", err)
+	}
+	if filepath.Base(frame.File) != "x.go" || frame.Line != 4 {
+		t.Errorf("Parallel frame = %s:%d, want x.go:4", frame.File, frame.Line)
+	}
+}
+
+func TestPool(t *testing.T) {
+	const limit = 2
+	pool := try.NewPool(limit)
+	var running, maxRunning atomic.Int32
+	for i := 0; i < 8; i++ {
+		i := i
+		pool.Go(func() {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				old := maxRunning.Load()
+				if n <= old || maxRunning.CompareAndSwap(old, n) {
+					break
+				}
+			}
+			if i%4 == 0 {
+				try.E(io.EOF)
+			}
+		})
+	}
+	err := pool.Wait()
+	if got := strings.Count(err.Error(), "EOF"); got != 2 {
+		t.Errorf("Wait() = %q, want 2 joined errors", err)
+	}
+	if maxRunning.Load() > limit {
+		t.Errorf("max running = %d, want at most %d", maxRunning.Load(), limit)
+	}
+
+	if err := try.NewPool(0).Wait(); err != nil {
+		t.Errorf("Wait() = %v, want nil", err)
+	}
+}
+
+func TestWaitGroup(t *testing.T) {
+	var wg try.WaitGroup
+	var n atomic.Int32
+	for i := 0; i < 4; i++ {
+		i := i
+		wg.Go(func() {
+			n.Add(1)
+			if i%2 == 0 {
+				try.E(io.EOF)
+			}
+		})
+	}
+	err := wg.Wait()
+	if n.Load() != 4 || strings.Count(err.Error(), "EOF") != 2 {
+		t.Errorf("Wait() = %q after %d calls, want 2 joined errors after 4 calls", err, n.Load())
+	}
+}
+
+func TestRace(t *testing.T) {
+	slow := func(ctx context.Context) string {
+		<-ctx.Done()
+		try.E(ctx.Err())
+		return "slow"
+	}
+	fast := func(ctx context.Context) string { return "fast" }
+	failing := func(ctx context.Context) string {
+		try.E(io.EOF)
+		return "failing"
+	}
+
+	if v, err := try.Race(context.Background(), slow, failing, fast); v != "fast" || err != nil {
+		t.Errorf("Race = (%q, %v), want (fast, nil)", v, err)
+	}
+	v, err := try.Race(context.Background(), failing, func(context.Context) string { panic("boom") })
+	if v != "" || !errors.Is(err, io.EOF) || !strings.HasSuffix(err.Error(), "\npanic: boom") {
+		t.Errorf("Race = (%q, %q), want joined EOF and panic: boom", v, err)
+	}
+	if _, err := try.Race[string](context.Background()); err == nil {
+		t.Errorf("Race without functions succeeded, want error")
+	}
+}
+
+func TestMapConcurrent(t *testing.T) {
+	var got []int
+	err := func() (err error) {
+		defer try.Handle(&err)
+		got = try.MapConcurrent([]string{"1", "2", "3"}, 2, func(s string) int {
+			return try.E1(strconv.Atoi(s))
+		})
+		return nil
+	}()
+	if want := []int{1, 2, 3}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("MapConcurrent = (%v, %v), want (%v, nil)", got, err, want)
+	}
+
+	err = func() (err error) {
+		defer try.Handle(&err)
+		try.MapConcurrent([]string{"1", "x", "y"}, 0, func(s string) int {
+			return try.E1(strconv.Atoi(s))
+		})
+		return nil
+	}()
+	if err == nil || strings.Count(err.Error(), "invalid syntax") != 2 || !strings.Contains(err.Error(), `"x"`) {
+		t.Errorf("MapConcurrent = %v, want 2 joined errors", err)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// Handler handles an error that occurred in the given frame.
+// It returns the error to report in its place, which may be nil.
+type Handler func(err error, frame runtime.Frame) error
+
+// Compose returns a Handler that calls each of handlers in order,
+// passing the error returned by one handler to the next.
+// Once a handler returns nil, the remaining handlers are not called.
+//
+//	var handleRPC = try.Compose(wrapRPC, classify, logError)
+func Compose(handlers ...Handler) Handler {
+	return func(err error, frame runtime.Frame) error {
+		for _, h := range handlers {
+			if err == nil {
+				break
+			}
+			err = h(err, frame)
+		}
+		return err
+	}
+}
+
+// HandleWith recovers an error previously panicked with an E function,
+// passes it through each of handlers as with Compose,
+// and stores the result into errptr.
+//
+//	func (s *Server) call() (err error) {
+//		defer try.HandleWith(&err, handleRPC)
+//		...
+//	}
+func HandleWith(errptr *error, handlers ...Handler) {
+	r(recover(), func(w *Error) {
+		checkErrptr(errptr, w)
+		*errptr = Compose(handlers...)(w.err, w.Frame())
+	})
+}
+
+// HandleChain is like HandleF, but calls each of fns in order after the error is stored,
+// where each function observes any modification of *errptr by those before it.
+// Once *errptr is nil, the remaining functions are not called.
+//
+//	func foo(i int) (err error) {
+//		defer try.HandleChain(&err, func() {
+//			err = fmt.Errorf("unable to foo %d: %w", i, err)
+//		}, func() {
+//			log.Print(err)
+//		})
+//		...
+//	}
+func HandleChain(errptr *error, fns ...func()) {
+	r(recover(), func(w *Error) {
+		checkErrptr(errptr, w)
+		*errptr = w.err
+		for _, fn := range fns {
+			if *errptr == nil {
+				break
+			}
+			fn()
+		}
+	})
+}
+
+// Rethrow recovers an error previously panicked with an E function,
+// calls fn with the error and the runtime frame in which it occurred,
+// and then panics again with the same error so that it can be recovered
+// by a handler deferred earlier in the same function or by a caller.
+// The error retains its original frame.
+//
+// When multiple handlers are deferred in one function, they run in
+// reverse order and only the first to run recovers the error.
+// Rethrow allows a handler that only observes the error, such as for logging,
+// to be layered on top of a handler that stores it:
+//
+//	func f() (err error) {
+//		defer try.Handle(&err)
+//		defer try.Rethrow(func(err error, frame runtime.Frame) {
+//			log.Printf("%s:%d: %v", frame.File, frame.Line, err)
+//		})
+//		...
+//	}
+//
+// The function set by SetOnRecover is not called by Rethrow,
+// but by the handler that ultimately recovers the error.
+func Rethrow(fn func(err error, frame runtime.Frame)) {
+	switch ex := recover().(type) {
+	case nil:
+	case *Error:
+		fn(ex.err, ex.Frame())
+		panic(ex)
+	default:
+		panic(ex)
+	}
+}
+
+var onRecover atomic.Pointer[func(err error, frame runtime.Frame)]
+
+// SetOnRecover sets a function that is called whenever a handler in this
+// package recovers an error panicked with an E function.
+// It is called with the error as originally panicked and the frame in which it occurred,
+// after the handler has stored or otherwise processed the error.
+// It is intended for telemetry, such as counting or sampling recovered errors,
+// and must be safe for concurrent use.
+// Passing nil removes any previously set function.
+func SetOnRecover(fn func(err error, frame runtime.Frame)) {
+	if fn == nil {
+		onRecover.Store(nil)
+	} else {
+		onRecover.Store(&fn)
+	}
+}
+
+// NotifyRecover calls the function set by SetOnRecover, if any, with e.
+// It is intended for handlers in other packages that recover an *Error
+// themselves, such as the one in package tryhttp, and should be called
+// after the handler has processed the error.
+func NotifyRecover(e *Error) {
+	notify(e)
+}
+
+// notify calls the function set by SetOnRecover, if any.
+func notify(w *Error) {
+	if fn := onRecover.Load(); fn != nil {
+		(*fn)(w.err, w.Frame())
+	}
+}
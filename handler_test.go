@@ -0,0 +1,155 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try_test
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/dsnet/try"
+)
+
+func TestHandleWith(t *testing.T) {
+	var calls []string
+	wrap := func(err error, frame runtime.Frame) error {
+		calls = append(calls, "wrap")
+		return fmt.Errorf("line %d: %w", frame.Line, err)
+	}
+	ignore := func(err error, frame runtime.Frame) error {
+		calls = append(calls, "ignore")
+		return nil
+	}
+	never := func(err error, frame runtime.Frame) error {
+		calls = append(calls, "never")
+		return err
+	}
+
+	err := func() (err error) {
+		defer try.HandleWith(&err, wrap, try.Compose(wrap))
+//line x.go:4
+		try.E(io.EOF)
+		return nil
+	}()
+	if want := "line 4: line 4: EOF"; err == nil || err.Error() != want {
+		t.Errorf("HandleWith: got %v, want %v", err, want)
+	}
+
+	calls = nil
+	err = func() (err error) {
+		defer try.HandleWith(&err, try.Compose(wrap, ignore), never)
+		try.E(io.EOF)
+		return nil
+	}()
+	if err != nil {
+		t.Errorf("HandleWith: got %v, want nil", err)
+	}
+	if want := []string{"wrap", "ignore"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
+
+func TestSetOnRecover(t *testing.T) {
+	var got []string
+	try.SetOnRecover(func(err error, frame runtime.Frame) {
+		got = append(got, fmt.Sprintf("%d: %v", frame.Line, err))
+	})
+	defer try.SetOnRecover(nil)
+
+	func() (err error) {
+		defer try.HandleIgnore(&err, io.EOF)
+//line x.go:4
+		try.E(io.EOF)
+		return nil
+	}()
+	func() {
+		defer try.F(func(...any) {})
+//line x.go:8
+		try.E(io.ErrUnexpectedEOF)
+	}()
+	func() (err error) {
+		defer try.HandleAny(&err)
+		panic("not a try panic")
+	}()
+	func() (err error) {
+		defer try.Handle(&err)
+		return nil
+	}()
+	if want := []string{"4: EOF", "8: unexpected EOF"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("OnRecover calls = %q, want %q", got, want)
+	}
+}
+
+func TestHandleChain(t *testing.T) {
+	var logged []string
+	err := func() (err error) {
+		defer try.HandleChain(&err, func() {
+			err = fmt.Errorf("unable to foo: %w", err)
+		}, func() {
+			logged = append(logged, err.Error())
+		})
+		try.E(io.EOF)
+		return nil
+	}()
+	if want := "unable to foo: EOF"; err == nil || err.Error() != want {
+		t.Errorf("HandleChain: got %v, want %v", err, want)
+	}
+	if want := []string{"unable to foo: EOF"}; !reflect.DeepEqual(logged, want) {
+		t.Errorf("logged = %q, want %q", logged, want)
+	}
+
+	logged = nil
+	err = func() (err error) {
+		defer try.HandleChain(&err, func() {
+			err = nil
+		}, func() {
+			logged = append(logged, err.Error())
+		})
+		try.E(io.EOF)
+		return nil
+	}()
+	if err != nil || len(logged) > 0 {
+		t.Errorf("HandleChain: got (%v, %q), want (nil, [])", err, logged)
+	}
+}
+
+func TestRethrow(t *testing.T) {
+	var notified int
+	try.SetOnRecover(func(err error, frame runtime.Frame) { notified++ })
+	defer try.SetOnRecover(nil)
+
+	var got []string
+	err := func() (err error) {
+		defer try.Handle(&err)
+		defer try.Rethrow(func(err error, frame runtime.Frame) {
+			got = append(got, fmt.Sprintf("outer %d: %v", frame.Line, err))
+		})
+		defer try.Rethrow(func(err error, frame runtime.Frame) {
+			got = append(got, fmt.Sprintf("inner %d: %v", frame.Line, err))
+		})
+//line x.go:4
+		try.E(io.EOF)
+		return nil
+	}()
+	if err != io.EOF {
+		t.Errorf("Rethrow: got %v, want %v", err, io.EOF)
+	}
+	if want := []string{"inner 4: EOF", "outer 4: EOF"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Rethrow calls = %q, want %q", got, want)
+	}
+	if notified != 1 {
+		t.Errorf("OnRecover called %d times, want 1", notified)
+	}
+
+	got = nil
+	func() {
+		defer try.Rethrow(func(err error, frame runtime.Frame) { got = append(got, "called") })
+	}()
+	if got != nil {
+		t.Errorf("Rethrow without panic called fn")
+	}
+}
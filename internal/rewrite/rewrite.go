@@ -0,0 +1,187 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+// Package rewrite implements the source rewriting shared by
+// the tryconvert and tryexpand commands.
+package rewrite
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TryPath is the import path of package try.
+const TryPath = "github.com/dsnet/try"
+
+// Main runs the command with the given name, which rewrites the Go files
+// in the paths given as arguments with fn, or standard input to standard
+// output if there are none. The function fn returns the rewritten source
+// and the number of changes made to it.
+// Main implements the -l and -w flags and exits if an error occurs.
+func Main(name string, fn func(filename string, src []byte) ([]byte, int, error)) {
+	log.SetFlags(0)
+	log.SetPrefix(name + ": ")
+
+	list := flag.Bool("l", false, "list files whose contents would change")
+	write := flag.Bool("w", false, "write the result to the source file")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			log.Fatal(err)
+		}
+		out, _, err := fn("<standard input>", src)
+		if err != nil {
+			log.Fatal(err)
+		}
+		os.Stdout.Write(out)
+		return
+	}
+	for _, path := range flag.Args() {
+		err := filepath.WalkDir(path, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(path, ".go") {
+				return err
+			}
+			src, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			out, n, err := fn(path, src)
+			if err != nil {
+				return err
+			}
+			switch {
+			case *list || *write:
+				if n > 0 && *list {
+					fmt.Println(path)
+				}
+				if n > 0 && *write {
+					return os.WriteFile(path, out, 0664)
+				}
+			default:
+				os.Stdout.Write(out)
+			}
+			return nil
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// Edit replaces the source between the offsets pos and end with text.
+// If to is beyond from, the text is followed by the source between
+// the offsets from and to, with the edits within it applied, and then by post.
+type Edit struct {
+	pos, end int
+	text     string
+	from, to int
+	post     string
+}
+
+// File is the source of a file together with the edits to apply to it.
+type File struct {
+	Fset  *token.FileSet
+	Src   []byte
+	Edits []Edit
+}
+
+// Replace returns an edit replacing the source between pos and end with text.
+func (f *File) Replace(pos, end token.Pos, text string) Edit {
+	tf := f.Fset.File(pos)
+	return Edit{pos: tf.Offset(pos), end: tf.Offset(end), text: text}
+}
+
+// Wrap returns an edit replacing the source between pos and end with
+// the source of node, including any edits within it, between text and post.
+func (f *File) Wrap(pos, end token.Pos, text string, node ast.Node, post string) Edit {
+	tf := f.Fset.File(pos)
+	return Edit{tf.Offset(pos), tf.Offset(end), text, tf.Offset(node.Pos()), tf.Offset(node.End()), post}
+}
+
+// Text returns the source text of node.
+func (f *File) Text(node ast.Node) string {
+	tf := f.Fset.File(node.Pos())
+	return string(f.Src[tf.Offset(node.Pos()):tf.Offset(node.End())])
+}
+
+// Apply returns the source with all edits applied,
+// including those within the source copied by other edits.
+// It reports an error if edits otherwise overlap.
+func (f *File) Apply() ([]byte, error) {
+	edits := append([]Edit(nil), f.Edits...)
+	sort.SliceStable(edits, func(i, j int) bool {
+		if edits[i].pos != edits[j].pos {
+			return edits[i].pos < edits[j].pos
+		}
+		return edits[i].end > edits[j].end // enclosing edits first
+	})
+	var b bytes.Buffer
+	if err := write(&b, f.Src, 0, len(f.Src), edits); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// write writes src[pos:end] to b with edits applied,
+// which must be sorted and lie within it.
+func write(b *bytes.Buffer, src []byte, pos, end int, edits []Edit) error {
+	for len(edits) > 0 {
+		ed := edits[0]
+		n := 1
+		for n < len(edits) && edits[n].pos < ed.end {
+			n++
+		}
+		inner := edits[1:n]
+		edits = edits[n:]
+		for _, in := range inner {
+			if in.pos < ed.from || in.end > ed.to {
+				return fmt.Errorf("overlapping edits at offsets %d and %d", ed.pos, in.pos)
+			}
+		}
+		b.Write(src[pos:ed.pos])
+		b.WriteString(ed.text)
+		if ed.to > ed.from {
+			if err := write(b, src, ed.from, ed.to, inner); err != nil {
+				return err
+			}
+		}
+		b.WriteString(ed.post)
+		pos = ed.end
+	}
+	b.Write(src[pos:end])
+	return nil
+}
+
+// TryImport returns the import of package try in f and the name
+// by which it is referenced, or nil if f does not import it.
+func TryImport(f *ast.File) (*ast.ImportSpec, string) {
+	for _, spec := range f.Imports {
+		if path, _ := strconv.Unquote(spec.Path.Value); path == TryPath {
+			if spec.Name != nil {
+				return spec, spec.Name.Name
+			}
+			return spec, "try"
+		}
+	}
+	return nil, ""
+}
+
+// IsIdent reports whether expr is an identifier with the given name.
+func IsIdent(expr ast.Expr, name string) bool {
+	id, ok := expr.(*ast.Ident)
+	return ok && id.Name == name
+}
@@ -0,0 +1,22 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package rewrite
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteOverlap(t *testing.T) {
+	var b bytes.Buffer
+	src := []byte("0123456789")
+	if err := write(&b, src, 0, len(src), []Edit{{pos: 0, end: 5, text: "x"}, {pos: 3, end: 8}}); err == nil {
+		t.Errorf("write succeeded with overlapping edits: %q", b.String())
+	}
+	b.Reset()
+	if err := write(&b, src, 0, len(src), []Edit{{pos: 0, end: 6, text: "<", from: 2, to: 5, post: ">"}, {pos: 3, end: 4, text: "x"}}); err != nil || b.String() != "<2x4>6789" {
+		t.Errorf("write = %q, %v, want %q", b.String(), err, "<2x4>6789")
+	}
+}
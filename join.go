@@ -0,0 +1,72 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try
+
+import "errors"
+
+// EJoin panics if any of errs is non-nil.
+// The panicked error joins all non-nil errors as with errors.Join.
+func EJoin(errs ...error) {
+	if err := errors.Join(errs...); err != nil {
+		throw(err)
+	}
+}
+
+// join joins a and b as with errors.Join,
+// but returns either as is if the other is nil.
+func join(a, b error) error {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	default:
+		return errors.Join(a, b)
+	}
+}
+
+// HandleJoin recovers an error previously panicked with an E function and
+// joins it with any error already stored in errptr as with errors.Join.
+// Unlike Handle, it does not discard an error assigned to errptr before the panic.
+func HandleJoin(errptr *error) {
+	r(recover(), func(w *Error) {
+		checkErrptr(errptr, w)
+		*errptr = join(*errptr, w.err)
+	})
+}
+
+// firstError is the first of two errors, which also retains the later error.
+type firstError struct {
+	first, later error
+}
+
+func (e firstError) Error() string   { return e.first.Error() }
+func (e firstError) Unwrap() []error { return []error{e.first, e.later} }
+
+// HandleFirst recovers an error previously panicked with an E function and stores it into errptr
+// only if errptr does not already hold a non-nil error.
+// Otherwise, the earlier error is preserved and reported as is by Error,
+// while the recovered error remains reachable with errors.Is and errors.As,
+// where the errors are matched earlier error first.
+//
+//	func f() (err error) {
+//		defer try.HandleFirst(&err)
+//		if !valid {
+//			err = errInvalid
+//		}
+//		try.E(cleanup())
+//		return err
+//	}
+func HandleFirst(errptr *error) {
+	r(recover(), func(w *Error) {
+		checkErrptr(errptr, w)
+		switch {
+		case *errptr == nil:
+			*errptr = w.err
+		default:
+			*errptr = firstError{*errptr, w.err}
+		}
+	})
+}
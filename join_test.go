@@ -0,0 +1,78 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+
+	"github.com/dsnet/try"
+)
+
+func TestEJoin(t *testing.T) {
+	var gotErr error
+	func() {
+		defer try.Handle(&gotErr)
+		try.EJoin()
+		try.EJoin(nil, nil)
+		try.EJoin(io.EOF, nil, fs.ErrNotExist)
+	}()
+	for _, want := range []error{io.EOF, fs.ErrNotExist} {
+		if !errors.Is(gotErr, want) {
+			t.Errorf("errors.Is(%v, %v) = false, want true", gotErr, want)
+		}
+	}
+}
+
+func TestHandleJoin(t *testing.T) {
+	err := func() (err error) {
+		defer try.HandleJoin(&err)
+		try.E(io.EOF)
+		return nil
+	}()
+	if err != io.EOF {
+		t.Errorf("HandleJoin: got %v, want %v", err, io.EOF)
+	}
+
+	err = func() (err error) {
+		defer try.HandleJoin(&err)
+		err = fs.ErrNotExist
+		try.E(io.EOF)
+		return nil
+	}()
+	for _, want := range []error{io.EOF, fs.ErrNotExist} {
+		if !errors.Is(err, want) {
+			t.Errorf("errors.Is(%v, %v) = false, want true", err, want)
+		}
+	}
+}
+
+func TestHandleFirst(t *testing.T) {
+	err := func() (err error) {
+		defer try.HandleFirst(&err)
+		try.E(io.EOF)
+		return nil
+	}()
+	if err != io.EOF {
+		t.Errorf("HandleFirst: got %v, want %v", err, io.EOF)
+	}
+
+	err = func() (err error) {
+		defer try.HandleFirst(&err)
+		err = fs.ErrNotExist
+		try.E(io.EOF)
+		return nil
+	}()
+	if err.Error() != fs.ErrNotExist.Error() {
+		t.Errorf("HandleFirst: got %q, want %q", err.Error(), fs.ErrNotExist.Error())
+	}
+	for _, want := range []error{io.EOF, fs.ErrNotExist} {
+		if !errors.Is(err, want) {
+			t.Errorf("errors.Is(%v, %v) = false, want true", err, want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try
+
+import (
+	"context"
+	"errors"
+	"log"
+	"log/slog"
+	"time"
+)
+
+// HandleLog recovers an error previously panicked with an E function and
+// logs it with logger, which reports the file and line in which the error
+// occurred according to its Lshortfile or Llongfile flags.
+// If errptr is non-nil, it also stores the error into errptr.
+//
+//	func process() (err error) {
+//		defer try.HandleLog(logger, &err)
+//		...
+//	}
+func HandleLog(logger *log.Logger, errptr *error) {
+	r(recover(), func(w *Error) {
+		if errptr != nil {
+			*errptr = w.err
+		}
+		// 1: the caller of Output
+		depth := 1
+		if n := depthOf(w); n >= 0 {
+			depth += n
+		}
+		logger.Output(depth, redact(w.err.Error()))
+	})
+}
+
+// HandleSlog recovers an error previously panicked with an E function and
+// logs it with logger at the given level, including any attributes
+// attached by EAttrs. If logger is nil, it uses slog.Default.
+// The source of the log record is the frame in which the error occurred,
+// which handlers report when configured with AddSource.
+// If errptr is non-nil, it also stores the error into errptr.
+//
+//	func (s *Server) handle(ctx context.Context) (err error) {
+//		defer try.HandleSlog(ctx, s.logger, slog.LevelError, &err)
+//		...
+//	}
+func HandleSlog(ctx context.Context, logger *slog.Logger, level slog.Level, errptr *error) {
+	r(recover(), func(w *Error) {
+		if errptr != nil {
+			*errptr = w.err
+		}
+		if logger == nil {
+			logger = slog.Default()
+		}
+		if !logger.Enabled(ctx, level) {
+			return
+		}
+		rec := slog.NewRecord(time.Now(), level, redact(w.err.Error()), w.PC())
+		rec.Add(Attrs(w.err)...)
+		logger.Handler().Handle(ctx, rec)
+	})
+}
+
+// LogValue implements slog.LogValuer.
+// It expands the error into a group with a "msg" attribute holding the
+// message of the underlying error and a "source" attribute holding
+// a *slog.Source for the frame in which it occurred.
+func (e *Error) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("msg", redact(e.err.Error())),
+		slog.Any("source", e.Source()),
+	)
+}
+
+// Source returns the frame in which the error occurred as a *slog.Source.
+func (e *Error) Source() *slog.Source {
+	frame := e.Frame()
+	return &slog.Source{Function: frame.Function, File: frame.File, Line: frame.Line}
+}
+
+// SourceOf returns the source of the first *Error in err's tree,
+// or nil if there is none.
+func SourceOf(err error) *slog.Source {
+	var e *Error
+	if !errors.As(err, &e) {
+		return nil
+	}
+	return e.Source()
+}
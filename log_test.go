@@ -0,0 +1,125 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dsnet/try"
+)
+
+func TestHandleLog(t *testing.T) {
+	buf := new(strings.Builder)
+	logger := log.New(buf, "", log.Lshortfile)
+	var err error
+	func() {
+		defer try.HandleLog(logger, &err)
+//line /full/path/to/y.go:10
+		try.E(io.EOF)
+	}()
+	if want := "y.go:10: EOF\n"; buf.String() != want {
+		t.Errorf("HandleLog logged %q, want %q", buf.String(), want)
+	}
+	if err != io.EOF {
+		t.Errorf("HandleLog stored %v, want %v", err, io.EOF)
+	}
+
+	buf.Reset()
+	func() {
+		defer try.HandleLog(logger, nil)
+//line /full/path/to/y.go:20
+		try.E(io.EOF)
+	}()
+	if want := "y.go:20: EOF\n"; buf.String() != want {
+		t.Errorf("HandleLog logged %q, want %q", buf.String(), want)
+	}
+}
+
+func TestHandleSlog(t *testing.T) {
+	buf := new(strings.Builder)
+	logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{AddSource: true}))
+	var err error
+	func() {
+		defer try.HandleSlog(context.Background(), logger, slog.LevelWarn, &err)
+		try.E(nil)
+//line /full/path/to/y.go:10
+		try.EAttrs(io.EOF, "path", "config.json")
+	}()
+	if !errors.Is(err, io.EOF) {
+		t.Errorf("HandleSlog stored %v, want %v", err, io.EOF)
+	}
+
+	var got struct {
+		Level  string
+		Msg    string
+		Path   string
+		Source slog.Source
+	}
+	if err := json.Unmarshal([]byte(buf.String()), &got); err != nil {
+		t.Fatalf("json.Unmarshal error: %v", err)
+	}
+	if got.Level != "WARN" || got.Msg != "EOF" || got.Path != "config.json" {
+		t.Errorf("HandleSlog logged %s", buf.String())
+	}
+	if filepath.Base(got.Source.File) != "y.go" || got.Source.Line != 10 || !strings.HasSuffix(got.Source.Function, ".TestHandleSlog.func1") {
+		t.Errorf("HandleSlog logged source %+v, want y.go:10 in TestHandleSlog.func1", got.Source)
+	}
+
+	buf.Reset()
+	func() {
+		defer try.HandleSlog(context.Background(), logger, slog.LevelDebug, nil)
+		try.E(io.EOF)
+	}()
+	if buf.Len() > 0 {
+		t.Errorf("HandleSlog logged disabled level: %s", buf.String())
+	}
+}
+
+func TestLogValue(t *testing.T) {
+	e := recordError(func() {
+//line /full/path/to/x.go:4
+		try.E(io.EOF)
+	})
+	buf := new(strings.Builder)
+	logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	logger.Error("failed", slog.Any("err", e))
+	const want = `{"level":"ERROR","msg":"failed","err":{"msg":"EOF","source":{"function":"github.com/dsnet/try_test.TestLogValue.func1","file":"/full/path/to/x.go","line":4}}}` + "\n"
+	if buf.String() != want {
+		t.Errorf("logged %s, want %s", buf.String(), want)
+	}
+}
+
+func TestSourceOf(t *testing.T) {
+	e := recordError(func() {
+//line /full/path/to/x.go:4
+		try.E(io.EOF)
+	})
+	want := slog.Source{Function: "github.com/dsnet/try_test.TestSourceOf.func1", File: "/full/path/to/x.go", Line: 4}
+	if got := e.Source(); *got != want {
+		t.Errorf("Source() = %+v, want %+v", *got, want)
+	}
+	if got := try.SourceOf(fmt.Errorf("wrapped: %w", e)); got == nil || *got != want {
+		t.Errorf("SourceOf(wrapped) = %+v, want %+v", got, want)
+	}
+	if got := try.SourceOf(io.EOF); got != nil {
+		t.Errorf("SourceOf(io.EOF) = %+v, want nil", got)
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try
+
+import "errors"
+
+// isAny reports whether err matches any of targets according to errors.Is.
+func isAny(err error, targets []error) bool {
+	for _, target := range targets {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// EIgnoring panics if err is non-nil and
+// does not match any of targets according to errors.Is.
+//
+//	try.EIgnoring(os.Remove(path), fs.ErrNotExist)
+func EIgnoring(err error, targets ...error) {
+	if err != nil && !isAny(err, targets) {
+		throw(err)
+	}
+}
+
+// E1Ignoring returns a as is.
+// It panics if err is non-nil and
+// does not match any of targets according to errors.Is.
+func E1Ignoring[A any](a A, err error, targets ...error) A {
+	if err != nil && !isAny(err, targets) {
+		throw(err)
+	}
+	return a
+}
+
+// EIs panics if err is non-nil.
+// If err matches target according to errors.Is,
+// it uses replacement in place of err.
+//
+//	try.EIs(io.ReadFull(r, b), io.EOF, io.ErrUnexpectedEOF)
+func EIs(err, target, replacement error) {
+	if err != nil && errors.Is(err, target) {
+		err = replacement
+	}
+	if err != nil {
+		throw(err)
+	}
+}
+
+// EUnless returns the error in err's tree that matches type E
+// according to errors.As, so that expected errors can be handled locally.
+// It panics if err is non-nil and has no such error.
+//
+//	if nf := try.EUnless[*NotFoundError](lookup(key)); nf != nil {
+//		...
+//	}
+func EUnless[E error](err error) E {
+	var target E
+	if err != nil && !errors.As(err, &target) {
+		throw(err)
+	}
+	return target
+}
+
+// E1Unless returns a as is alongside the error in err's tree that
+// matches type E according to errors.As.
+// It panics if err is non-nil and has no such error.
+func E1Unless[E error, A any](a A, err error) (A, E) {
+	var target E
+	if err != nil && !errors.As(err, &target) {
+		throw(err)
+	}
+	return a, target
+}
+
+// EMap panics if err is non-nil, using fn(err) in place of err.
+// It does not panic if fn returns nil.
+//
+//	try.EMap(db.Exec(query), func(err error) error {
+//		var pqErr *pq.Error
+//		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+//			return ErrDuplicate
+//		}
+//		return err
+//	})
+func EMap(err error, fn func(error) error) {
+	if err != nil {
+		if err = fn(err); err != nil {
+			throw(err)
+		}
+	}
+}
+
+// E1Map returns a as is.
+// It panics if err is non-nil, using fn(err) in place of err as with EMap.
+func E1Map[A any](a A, err error, fn func(error) error) A {
+	if err != nil {
+		if err = fn(err); err != nil {
+			throw(err)
+		}
+	}
+	return a
+}
+
+// E2Map returns a and b as is.
+// It panics if err is non-nil, using fn(err) in place of err as with EMap.
+func E2Map[A, B any](a A, b B, err error, fn func(error) error) (A, B) {
+	if err != nil {
+		if err = fn(err); err != nil {
+			throw(err)
+		}
+	}
+	return a, b
+}
+
+// E3Map returns a, b, and c as is.
+// It panics if err is non-nil, using fn(err) in place of err as with EMap.
+func E3Map[A, B, C any](a A, b B, c C, err error, fn func(error) error) (A, B, C) {
+	if err != nil {
+		if err = fn(err); err != nil {
+			throw(err)
+		}
+	}
+	return a, b, c
+}
+
+// E4Map returns a, b, c, and d as is.
+// It panics if err is non-nil, using fn(err) in place of err as with EMap.
+func E4Map[A, B, C, D any](a A, b B, c C, d D, err error, fn func(error) error) (A, B, C, D) {
+	if err != nil {
+		if err = fn(err); err != nil {
+			throw(err)
+		}
+	}
+	return a, b, c, d
+}
+
+// HandleIs recovers an error previously panicked with an E function and stores it into errptr.
+// The pairs are alternating target and replacement errors,
+// where the first target that the recovered error matches according to errors.Is
+// causes its replacement to be stored instead.
+//
+//	func f() (err error) {
+//		defer try.HandleIs(&err, io.EOF, io.ErrUnexpectedEOF)
+//		...
+//	}
+func HandleIs(errptr *error, pairs ...error) {
+	if len(pairs)%2 != 0 {
+		panic("try: HandleIs called with an odd number of errors")
+	}
+	r(recover(), func(w *Error) {
+		checkErrptr(errptr, w)
+		*errptr = w.err
+		for i := 0; i < len(pairs); i += 2 {
+			if errors.Is(w.err, pairs[i]) {
+				*errptr = pairs[i+1]
+				break
+			}
+		}
+	})
+}
+
+// HandleIgnore recovers an error previously panicked with an E function and stores it into errptr.
+// If the recovered error matches any of targets according to errors.Is,
+// it stores nil instead, treating the failure as success.
+//
+//	func cleanup() (err error) {
+//		defer try.HandleIgnore(&err, fs.ErrNotExist)
+//		...
+//	}
+func HandleIgnore(errptr *error, targets ...error) {
+	r(recover(), func(w *Error) {
+		checkErrptr(errptr, w)
+		*errptr = w.err
+		if isAny(w.err, targets) {
+			*errptr = nil
+		}
+	})
+}
+
+// HandleAs recovers an error previously panicked with an E function and stores it into errptr.
+// If the recovered error has an error in its tree that matches type T
+// according to errors.As, it stores the result of calling fn with that error instead.
+//
+//	func parse(b []byte) (err error) {
+//		defer try.HandleAs(&err, func(err *json.SyntaxError) error {
+//			return fmt.Errorf("invalid JSON at offset %d: %w", err.Offset, err)
+//		})
+//		...
+//	}
+func HandleAs[T error](errptr *error, fn func(T) error) {
+	r(recover(), func(w *Error) {
+		checkErrptr(errptr, w)
+		*errptr = w.err
+		var target T
+		if errors.As(w.err, &target) {
+			*errptr = fn(target)
+		}
+	})
+}
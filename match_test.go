@@ -0,0 +1,179 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"testing"
+
+	"github.com/dsnet/try"
+)
+
+func TestEIgnoring(t *testing.T) {
+	var gotErr error
+	func() {
+		defer try.Handle(&gotErr)
+		try.EIgnoring(nil, io.EOF)
+		try.EIgnoring(io.EOF, fs.ErrNotExist, io.EOF)
+		if v := try.E1Ignoring(5, io.EOF, io.EOF); v != 5 {
+			t.Errorf("E1Ignoring(5, EOF, EOF) = %v, want 5", v)
+		}
+		try.EIgnoring(io.ErrUnexpectedEOF, io.EOF)
+	}()
+	if !errors.Is(gotErr, io.ErrUnexpectedEOF) {
+		t.Errorf("recovered error: got %v, want %v", gotErr, io.ErrUnexpectedEOF)
+	}
+}
+
+func TestEIs(t *testing.T) {
+	tests := []struct {
+		in   error
+		want error
+	}{
+		{in: io.EOF, want: io.ErrUnexpectedEOF},
+		{in: fs.ErrNotExist, want: fs.ErrNotExist},
+	}
+	for _, tt := range tests {
+		var gotErr error
+		func() {
+			defer try.Handle(&gotErr)
+			try.EIs(nil, io.EOF, io.ErrUnexpectedEOF)
+			try.EIs(tt.in, io.EOF, io.ErrUnexpectedEOF)
+		}()
+		if gotErr != tt.want {
+			t.Errorf("recovered error: got %v, want %v", gotErr, tt.want)
+		}
+	}
+}
+
+type notFoundError struct{ key string }
+
+func (e *notFoundError) Error() string { return e.key + " not found" }
+
+func TestEUnless(t *testing.T) {
+	lookup := func(key string) (int, error) {
+		switch key {
+		case "foo":
+			return 1, nil
+		case "bar":
+			return 0, &notFoundError{key}
+		default:
+			return 0, io.ErrUnexpectedEOF
+		}
+	}
+
+	var gotErr error
+	func() {
+		defer try.Handle(&gotErr)
+		if nf := try.EUnless[*notFoundError](nil); nf != nil {
+			t.Errorf("EUnless(nil) = %v, want nil", nf)
+		}
+		if v, nf := try.E1Unless[*notFoundError](lookup("foo")); v != 1 || nf != nil {
+			t.Errorf("E1Unless(lookup(foo)) = (%v, %v), want (1, nil)", v, nf)
+		}
+		if _, nf := try.E1Unless[*notFoundError](lookup("bar")); nf == nil || nf.key != "bar" {
+			t.Errorf("E1Unless(lookup(bar)) = %v, want bar not found", nf)
+		}
+		try.E1Unless[*notFoundError](lookup("baz"))
+	}()
+	if gotErr != io.ErrUnexpectedEOF {
+		t.Errorf("recovered error: got %v, want %v", gotErr, io.ErrUnexpectedEOF)
+	}
+}
+
+func TestEMap(t *testing.T) {
+	errDomain := errors.New("domain error")
+	toDomain := func(err error) error {
+		if errors.Is(err, io.EOF) {
+			return errDomain
+		}
+		return nil
+	}
+
+	var gotErr error
+	func() {
+		defer try.Handle(&gotErr)
+		try.EMap(nil, toDomain)
+		try.EMap(fs.ErrNotExist, toDomain)
+		if v := try.E1Map(5, nil, toDomain); v != 5 {
+			t.Errorf("E1Map(5, nil) = %v, want 5", v)
+		}
+		v := try.E1Map(5, io.EOF, toDomain)
+		t.Errorf("E1Map(5, EOF) = %v, want panic", v)
+	}()
+	if gotErr != errDomain {
+		t.Errorf("recovered error: got %v, want %v", gotErr, errDomain)
+	}
+}
+
+func TestHandleIs(t *testing.T) {
+	errMissing := errors.New("missing")
+	tests := []struct {
+		in   error
+		want error
+	}{
+		{in: io.EOF, want: io.ErrUnexpectedEOF},
+		{in: fs.ErrNotExist, want: errMissing},
+		{in: fs.ErrPermission, want: fs.ErrPermission},
+	}
+	for _, tt := range tests {
+		err := func() (err error) {
+			defer try.HandleIs(&err, io.EOF, io.ErrUnexpectedEOF, fs.ErrNotExist, errMissing)
+			try.E(tt.in)
+			return nil
+		}()
+		if err != tt.want {
+			t.Errorf("HandleIs(%v): got %v, want %v", tt.in, err, tt.want)
+		}
+	}
+}
+
+func TestHandleIgnore(t *testing.T) {
+	tests := []struct {
+		in   error
+		want error
+	}{
+		{in: io.EOF, want: nil},
+		{in: fs.ErrNotExist, want: nil},
+		{in: fs.ErrPermission, want: fs.ErrPermission},
+	}
+	for _, tt := range tests {
+		err := func() (err error) {
+			defer try.HandleIgnore(&err, fs.ErrNotExist, io.EOF)
+			try.E(tt.in)
+			return nil
+		}()
+		if err != tt.want {
+			t.Errorf("HandleIgnore(%v): got %v, want %v", tt.in, err, tt.want)
+		}
+	}
+}
+
+func TestHandleAs(t *testing.T) {
+	errMissing := errors.New("missing")
+	tests := []struct {
+		in   error
+		want string
+	}{
+		{in: &notFoundError{"foo"}, want: "foo: missing"},
+		{in: fmt.Errorf("lookup: %w", &notFoundError{"bar"}), want: "bar: missing"},
+		{in: io.EOF, want: "EOF"},
+	}
+	for _, tt := range tests {
+		err := func() (err error) {
+			defer try.HandleAs(&err, func(err *notFoundError) error {
+				return fmt.Errorf("%s: %w", err.key, errMissing)
+			})
+			try.E(tt.in)
+			return nil
+		}()
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("HandleAs(%v): got %v, want %v", tt.in, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Incrementer is a counter keyed by name, such as a metrics counter vector.
+type Incrementer interface {
+	Inc(name string)
+}
+
+// classify returns the name of the Go type of the outermost error in err's
+// chain of single Unwrap methods that is not a wrapper made by fmt.Errorf,
+// which adds only context to the message.
+func classify(err error) string {
+	for {
+		name := fmt.Sprintf("%T", err)
+		inner := errors.Unwrap(err)
+		if inner == nil || name != "*fmt.wrapError" {
+			return name
+		}
+		err = inner
+	}
+}
+
+// HandleMetrics recovers an error previously panicked with an E function and stores it into errptr.
+// If it recovers an error, it increments counter with the classification of the error,
+// which is the Go type of the outermost error other than those made by
+// fmt.Errorf with a single %w verb (e.g., "*fs.PathError" for an error
+// returned by os.Open, even if wrapped with fmt.Errorf, or "*errors.errorString").
+//
+//	func (s *Server) handle() (err error) {
+//		defer try.HandleMetrics(&err, s.recoveredErrors)
+//		...
+//	}
+func HandleMetrics(errptr *error, counter Incrementer) {
+	r(recover(), func(w *Error) {
+		checkErrptr(errptr, w)
+		*errptr = w.err
+		counter.Inc(classify(w.err))
+	})
+}
@@ -0,0 +1,46 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try_test
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"reflect"
+	"testing"
+
+	"github.com/dsnet/try"
+)
+
+type counter map[string]int
+
+func (c counter) Inc(name string) { c[name]++ }
+
+func TestHandleMetrics(t *testing.T) {
+	c := make(counter)
+	for _, in := range []error{
+		nil,
+		io.EOF,
+		fmt.Errorf("wrapped: %w", io.EOF),
+		&fs.PathError{Op: "open", Path: "x", Err: fs.ErrNotExist},
+		fmt.Errorf("wrapped: %w", &fs.PathError{Op: "open", Path: "x", Err: fs.ErrNotExist}),
+		fmt.Errorf("wrapped: %w and %w", io.EOF, io.ErrUnexpectedEOF),
+	} {
+		err := func() (err error) {
+			defer try.HandleMetrics(&err, c)
+			try.E(in)
+			return nil
+		}()
+		if err != in {
+			t.Errorf("HandleMetrics: got %v, want %v", err, in)
+		}
+	}
+	// Wrappers made by fmt.Errorf are skipped, but not other wrappers
+	// such as *fs.PathError, nor errors that wrap multiple errors.
+	want := counter{"*errors.errorString": 2, "*fs.PathError": 2, "*fmt.wrapErrors": 1}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("counts = %v, want %v", c, want)
+	}
+}
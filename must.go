@@ -0,0 +1,53 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try
+
+// Must panics with err as is if it is non-nil.
+// Unlike E, the panic is not recoverable by the handlers in this package.
+// It is intended for package-level variable initialization and init functions,
+// where no handler can run and a failure should crash the program.
+//
+//	var tmpl = try.Must1(template.ParseFS(files, "*.tmpl"))
+func Must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Must1 returns a as is.
+// It panics with err as is if it is non-nil.
+func Must1[A any](a A, err error) A {
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// Must2 returns a and b as is.
+// It panics with err as is if it is non-nil.
+func Must2[A, B any](a A, b B, err error) (A, B) {
+	if err != nil {
+		panic(err)
+	}
+	return a, b
+}
+
+// Must3 returns a, b, and c as is.
+// It panics with err as is if it is non-nil.
+func Must3[A, B, C any](a A, b B, c C, err error) (A, B, C) {
+	if err != nil {
+		panic(err)
+	}
+	return a, b, c
+}
+
+// Must4 returns a, b, c, and d as is.
+// It panics with err as is if it is non-nil.
+func Must4[A, B, C, D any](a A, b B, c C, d D, err error) (A, B, C, D) {
+	if err != nil {
+		panic(err)
+	}
+	return a, b, c, d
+}
@@ -0,0 +1,36 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/dsnet/try"
+)
+
+func TestMust(t *testing.T) {
+	var got any
+	func() {
+		defer func() { got = recover() }()
+		if a, b, c := try.Must3(success()); a != 1 || b != "success" || c != true {
+			t.Errorf("Must3(success()) = (%v, %v, %v), want (1, success, true)", a, b, c)
+		}
+		try.Must(io.EOF)
+	}()
+	if got != io.EOF {
+		t.Errorf("recovered value: got %v, want %v", got, io.EOF)
+	}
+
+	var err error
+	func() {
+		defer func() { got = recover() }()
+		defer try.Handle(&err)
+		try.Must3(failure())
+	}()
+	if err != nil || got != io.EOF {
+		t.Errorf("Handle intercepted Must panic: got (%v, %v), want (nil, %v)", err, got, io.EOF)
+	}
+}
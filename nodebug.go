@@ -0,0 +1,13 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+//go:build !trydebug
+
+package try
+
+const debugMode = false
+
+func goid() uint64 { return 0 }
+
+func crashHint(*Error) string { return "" }
@@ -0,0 +1,74 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrNotOK is the error panicked by the OK functions when ok is false.
+var ErrNotOK = errors.New("not ok")
+
+// OK panics with ErrNotOK if ok is false.
+func OK(ok bool) {
+	if !ok {
+		throw(ErrNotOK)
+	}
+}
+
+// OK1 returns a as is.
+// It panics with an error wrapping ErrNotOK if ok is false.
+// It is the counterpart of E1 for functions that report success as a bool,
+// such as os.LookupEnv.
+func OK1[A any](a A, ok bool) A {
+	if !ok {
+		throw(fmt.Errorf("%w for value of type %T", ErrNotOK, a))
+	}
+	return a
+}
+
+// keyError reports a key that is missing from a map.
+type keyError struct{ key any }
+
+func (e keyError) Error() string        { return fmt.Sprintf("key %#v not found", e.key) }
+func (e keyError) Is(target error) bool { return target == ErrNotOK }
+
+// MapGet returns the value in m for key k.
+// It panics with an error naming the key and wrapping ErrNotOK
+// if k is not present in m.
+func MapGet[K comparable, V any, M ~map[K]V](m M, k K) V {
+	v, ok := m[k]
+	if !ok {
+		throw(keyError{k})
+	}
+	return v
+}
+
+// assertError reports a failed type assertion.
+type assertError struct {
+	got  any
+	want reflect.Type
+}
+
+func (e assertError) Error() string {
+	if e.got == nil {
+		return fmt.Sprintf("nil value is not %v", e.want)
+	}
+	return fmt.Sprintf("value of type %T is not %v", e.got, e.want)
+}
+func (e assertError) Is(target error) bool { return target == ErrNotOK }
+
+// Assert returns v asserted as type T.
+// It panics with an error reporting the dynamic type of v and
+// wrapping ErrNotOK if v does not hold a value of type T.
+func Assert[T any](v any) T {
+	t, ok := v.(T)
+	if !ok {
+		throw(assertError{v, reflect.TypeOf((*T)(nil)).Elem()})
+	}
+	return t
+}
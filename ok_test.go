@@ -0,0 +1,97 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try_test
+
+import (
+	"errors"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/dsnet/try"
+)
+
+func TestOK(t *testing.T) {
+	m := map[string]int{"foo": 1}
+	lookup := func(k string) (int, bool) {
+		v, ok := m[k]
+		return v, ok
+	}
+
+	var gotErr error
+	func() {
+		defer try.Recover(func(err error, frame runtime.Frame) {
+			gotErr = err
+			if filepath.Base(frame.File) != "x.go" {
+				t.Errorf("want File=x.go, got %q", frame.File)
+			}
+			if frame.Line != 4 {
+				t.Errorf("want Line=4, got %d", frame.Line)
+			}
+		})
+		try.OK(len(m) == 1)
+		if v := try.OK1(lookup("foo")); v != 1 {
+			t.Errorf("OK1(lookup(foo)) = %v, want 1", v)
+		}
+//line x.go:4
+		v := try.OK1(lookup("bar"))
+		t.Errorf("OK1(lookup(bar)) = %v, want panic", v)
+	}()
+	const want = "not ok for value of type int"
+	if gotErr == nil || gotErr.Error() != want {
+		t.Errorf("recovered error: got %v, want %v", gotErr, want)
+	}
+	if !errors.Is(gotErr, try.ErrNotOK) {
+		t.Errorf("errors.Is(%v, ErrNotOK) = false, want true", gotErr)
+	}
+}
+
+func TestMapGet(t *testing.T) {
+	m := map[string]int{"foo": 1}
+
+	var gotErr error
+	func() {
+		defer try.Handle(&gotErr)
+		if v := try.MapGet(m, "foo"); v != 1 {
+			t.Errorf("MapGet(m, foo) = %v, want 1", v)
+		}
+		v := try.MapGet(m, "bar")
+		t.Errorf("MapGet(m, bar) = %v, want panic", v)
+	}()
+	const want = `key "bar" not found`
+	if gotErr == nil || gotErr.Error() != want {
+		t.Errorf("recovered error: got %v, want %v", gotErr, want)
+	}
+	if !errors.Is(gotErr, try.ErrNotOK) {
+		t.Errorf("errors.Is(%v, ErrNotOK) = false, want true", gotErr)
+	}
+}
+
+func TestAssert(t *testing.T) {
+	tests := []struct {
+		v    any
+		want string
+	}{
+		{v: 5, want: "value of type int is not string"},
+		{v: nil, want: "nil value is not string"},
+	}
+	for _, tt := range tests {
+		var gotErr error
+		func() {
+			defer try.Handle(&gotErr)
+			if s := try.Assert[string](any("hello")); s != "hello" {
+				t.Errorf("Assert[string](hello) = %v, want hello", s)
+			}
+			s := try.Assert[string](tt.v)
+			t.Errorf("Assert[string](%v) = %v, want panic", tt.v, s)
+		}()
+		if gotErr == nil || gotErr.Error() != tt.want {
+			t.Errorf("recovered error: got %v, want %v", gotErr, tt.want)
+		}
+		if !errors.Is(gotErr, try.ErrNotOK) {
+			t.Errorf("errors.Is(%v, ErrNotOK) = false, want true", gotErr)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// panicError converts a recovered panic value into an error.
+// An error panicked by an E function is returned with its frame intact.
+func panicError(v any) error {
+	switch v := v.(type) {
+	case *Error:
+		return v
+	case error:
+		return fmt.Errorf("panic: %w", v)
+	default:
+		return fmt.Errorf("panic: %v", v)
+	}
+}
+
+// HandleAny recovers from any panic and stores it into errptr as an error.
+// Unlike Handle, an error panicked with an E function is stored with
+// the file and line in which it occurred, while any other panic value
+// is converted into an error with a "panic: " prefix.
+//
+//	func work() (err error) {
+//		defer try.HandleAny(&err)
+//		...
+//	}
+func HandleAny(errptr *error) {
+	if v := recover(); v != nil {
+		if w, ok := v.(*Error); ok {
+			defer notify(w)
+		}
+		err := panicError(v)
+		checkErrptr(errptr, err)
+		*errptr = err
+	}
+}
+
+// RecoverAny is like Recover, but also recovers from any other panic.
+// If it recovers an error panicked with an E function, it calls fn with
+// the error and the runtime frame in which it occurred.
+// Otherwise, it calls panicFn with the recovered value and
+// a formatted stack trace of the panicking goroutine.
+//
+//	func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+//		defer try.RecoverAny(func(err error, frame runtime.Frame) {
+//			// handle an anticipated failure
+//		}, func(v any, stack []byte) {
+//			// handle a programming bug
+//		})
+//		...
+//	}
+func RecoverAny(fn func(err error, frame runtime.Frame), panicFn func(v any, stack []byte)) {
+	switch v := recover().(type) {
+	case nil:
+	case *Error:
+		defer notify(v)
+		fn(v.err, v.Frame())
+	default:
+		panicFn(v, debug.Stack())
+	}
+}
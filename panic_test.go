@@ -0,0 +1,100 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try_test
+
+import (
+	"errors"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/dsnet/try"
+)
+
+func TestHandleAny(t *testing.T) {
+	tests := []struct {
+		name      string
+		run       func()
+		wantError string
+		wantIs    error
+	}{{
+		name: "Success",
+		run:  func() {},
+	}, {
+		name: "Try",
+		run: func() {
+//line /full/path/to/x.go:4
+			try.E(io.EOF)
+		},
+		wantError: "x.go:4: try_test.TestHandleAny.func2: EOF",
+		wantIs:    io.EOF,
+	}, {
+		name:      "Error",
+		run:       func() { panic(io.ErrUnexpectedEOF) },
+		wantError: "panic: unexpected EOF",
+		wantIs:    io.ErrUnexpectedEOF,
+	}, {
+		name:      "Value",
+		run:       func() { panic("crash and burn") },
+		wantError: "panic: crash and burn",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := func() (err error) {
+				defer try.HandleAny(&err)
+				tt.run()
+				return nil
+			}()
+			var gotError string
+			if err != nil {
+				gotError = try.TrimDebug(err.Error())
+			}
+			if gotError != tt.wantError {
+				t.Errorf("HandleAny: got %q, want %q", gotError, tt.wantError)
+			}
+			if tt.wantIs != nil && !errors.Is(err, tt.wantIs) {
+				t.Errorf("errors.Is(%v, %v) = false, want true", err, tt.wantIs)
+			}
+		})
+	}
+}
+
+func TestRecoverAny(t *testing.T) {
+	var gotErr error
+	var gotLine int
+	func() {
+		defer try.RecoverAny(func(err error, frame runtime.Frame) {
+			gotErr, gotLine = err, frame.Line
+		}, func(v any, stack []byte) {
+			t.Errorf("panicFn called with %v", v)
+		})
+//line x.go:4
+		try.E(io.EOF)
+	}()
+	if gotErr != io.EOF || gotLine != 4 {
+		t.Errorf("RecoverAny: got (%v, %d), want (%v, 4)", gotErr, gotLine, io.EOF)
+	}
+
+	var gotValue any
+	var gotStack string
+	func() {
+		defer try.RecoverAny(func(err error, frame runtime.Frame) {
+			t.Errorf("fn called with %v", err)
+		}, func(v any, stack []byte) {
+			gotValue, gotStack = v, string(stack)
+		})
+		panicky()
+	}()
+	if gotValue != "crash and burn" {
+		t.Errorf("RecoverAny: got %v, want crash and burn", gotValue)
+	}
+	if !strings.Contains(gotStack, "panicky") {
+		t.Errorf("RecoverAny stack does not mention panicking function:\n%s", gotStack)
+	}
+}
+
+//go:noinline
+func panicky() { panic("crash and burn") }
@@ -0,0 +1,55 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try
+
+// Stage starts a pipeline stage in g that calls f with each value received from in
+// and sends the result on the returned channel, which is closed once in is closed.
+// The function f may use the E functions. If f or any other function in g fails,
+// the stage stops and closes its output so that the pipeline shuts down,
+// and the first error is reported by g.Wait.
+// Producers of in should likewise select on g.Done when sending.
+//
+//	var g try.Group
+//	paths := make(chan string)
+//	g.Go(func() {
+//		defer close(paths)
+//		for _, p := range list {
+//			select {
+//			case paths <- p:
+//			case <-g.Done():
+//				return
+//			}
+//		}
+//	})
+//	files := try.Stage(&g, paths, func(p string) []byte { return try.E1(os.ReadFile(p)) })
+//	sums := try.Stage(&g, files, func(b []byte) [32]byte { return sha256.Sum256(b) })
+//	for sum := range sums {
+//		...
+//	}
+//	try.E(g.Wait())
+func Stage[T, U any](g *Group, in <-chan T, f func(T) U) <-chan U {
+	out := make(chan U)
+	done := g.Done()
+	g.Go(func() {
+		defer close(out)
+		for {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					return
+				}
+				u := f(v)
+				select {
+				case out <- u:
+				case <-done:
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	})
+	return out
+}
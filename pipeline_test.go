@@ -0,0 +1,59 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try_test
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/dsnet/try"
+)
+
+// produce sends each of vs on the returned channel until g fails.
+func produce[T any](g *try.Group, vs ...T) <-chan T {
+	out := make(chan T)
+	g.Go(func() {
+		defer close(out)
+		for _, v := range vs {
+			select {
+			case out <- v:
+			case <-g.Done():
+				return
+			}
+		}
+	})
+	return out
+}
+
+func TestStage(t *testing.T) {
+	var g try.Group
+	nums := try.Stage(&g, produce(&g, "1", "2", "3"), func(s string) int { return try.E1(strconv.Atoi(s)) })
+	squares := try.Stage(&g, nums, func(n int) int { return n * n })
+	var got []int
+	for n := range squares {
+		got = append(got, n)
+	}
+	if err := g.Wait(); err != nil {
+		t.Errorf("Wait() = %v, want nil", err)
+	}
+	if want := []int{1, 4, 9}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Stage results = %v, want %v", got, want)
+	}
+
+	// A failure in a later stage shuts down the earlier ones.
+	var g2 try.Group
+	words := make([]string, 100)
+	nums = try.Stage(&g2, produce(&g2, words...), func(s string) int { return len(s) })
+	_ = try.Stage(&g2, nums, func(n int) int {
+		try.E(io.EOF)
+		return n
+	})
+	if err := g2.Wait(); !errors.Is(err, io.EOF) {
+		t.Errorf("Wait() = %v, want %v", err, io.EOF)
+	}
+}
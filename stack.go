@@ -0,0 +1,361 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+)
+
+var (
+	maxStackDepth  atomic.Int32
+	preserveOrigin atomic.Bool
+)
+
+// SetStackDepth sets the maximum number of frames that the E functions record
+// when panicking with an error, which are reported by Error.Stack.
+// Frames are recorded starting at the call in which the error occurred.
+// The default and minimum depth is 1, which records only that call.
+// It is intended to be called during program initialization.
+func SetStackDepth(n int) {
+	if n < 1 {
+		n = 1
+	}
+	maxStackDepth.Store(int32(n))
+}
+
+// stackDepth returns the depth set by SetStackDepth.
+func stackDepth() int {
+	if n := maxStackDepth.Load(); n > 1 {
+		return int(n)
+	}
+	return 1
+}
+
+// Stack returns the frames recorded when the error occurred,
+// starting at the frame in which it occurred.
+// Unless SetStackDepth was called with a depth greater than 1,
+// only that frame is recorded. Inlined calls are reported as separate frames.
+func (e *Error) Stack() []runtime.Frame {
+	var stack []runtime.Frame
+	frames := runtime.CallersFrames(e.pcs)
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "runtime.goexit" {
+			stack = append(stack, frame)
+		}
+		if !more {
+			return stack
+		}
+	}
+}
+
+// callers returns the program counters of the current goroutine,
+// skipping the first skip frames.
+func callers(skip int) []uintptr {
+	pcs := make([]uintptr, 64)
+	for {
+		// 2: runtime.Callers, callers
+		n := runtime.Callers(2+skip, pcs)
+		if n < len(pcs) {
+			return pcs[:n]
+		}
+		pcs = make([]uintptr, 2*len(pcs))
+	}
+}
+
+// sameFrame reports whether a and b refer to the same source location.
+func sameFrame(a, b runtime.Frame) bool {
+	return a.Function == b.Function && a.File == b.File && a.Line == b.Line
+}
+
+// depthOf returns the number of frames above the caller of depthOf
+// until the frame in which w occurred, or -1 if it cannot be found.
+// It must be called while still panicking with w.
+func depthOf(w *Error) int {
+	site := w.Frame()
+	frames := runtime.CallersFrames(callers(1))
+	for i := 0; ; i++ {
+		frame, more := frames.Next()
+		if sameFrame(frame, site) {
+			return i
+		}
+		if !more {
+			return -1
+		}
+	}
+}
+
+// stackOf returns the frames of the current goroutine that are
+// at or above the frame in which w occurred.
+// It must be called while still panicking with w.
+func stackOf(w *Error) []runtime.Frame {
+	site := w.Frame()
+
+	var stack []runtime.Frame
+	frames := runtime.CallersFrames(callers(0))
+	for {
+		frame, more := frames.Next()
+		if len(stack) == 0 && sameFrame(frame, site) {
+			stack = append(stack, frame)
+		} else if len(stack) > 0 && frame.Function != "runtime.goexit" {
+			stack = append(stack, frame)
+		}
+		if !more {
+			break
+		}
+	}
+	if len(stack) == 0 {
+		stack = append(stack, site)
+	}
+	return stack
+}
+
+// HandleStack recovers an error previously panicked with an E function and stores it into errptr.
+// If it recovers an error, it calls fn with the stack of frames
+// starting at the frame in which the error occurred and
+// ending at the root of the goroutine.
+//
+//	func serve() (err error) {
+//		defer try.HandleStack(&err, func(stack []runtime.Frame) {
+//			for _, frame := range stack {
+//				log.Printf("\t%s\n\t\t%s:%d", frame.Function, frame.File, frame.Line)
+//			}
+//		})
+//		...
+//	}
+func HandleStack(errptr *error, fn func(stack []runtime.Frame)) {
+	r(recover(), func(w *Error) {
+		checkErrptr(errptr, w)
+		*errptr = w.err
+		fn(stackOf(w))
+	})
+}
+
+// handlerOf returns the frame of the innermost function at or above
+// the frame in which w occurred whose source starts before the declaration
+// of the function literal fn in the same file and, if it is running in that
+// file, is running past the declaration. The functions are compared by the
+// positions of their entry points rather than by their names, which the
+// runtime does not specify for function literals.
+// It returns the zero frame if no such frame can be found.
+// It must be called while still panicking with w.
+func handlerOf(w *Error, fn any) runtime.Frame {
+	lit := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if lit == nil {
+		return runtime.Frame{}
+	}
+	file, line := lit.FileLine(lit.Entry())
+	for _, frame := range stackOf(w) {
+		// Functions with deferred calls are not inlined,
+		// so the handler frame always has a Func.
+		if frame.Func == nil || frame.Func.Entry() == lit.Entry() {
+			continue
+		}
+		startFile, start := frame.Func.FileLine(frame.Func.Entry())
+		if startFile != file || start > line || frame.File == file && frame.Line < line {
+			continue
+		}
+		return frame
+	}
+	return runtime.Frame{}
+}
+
+// RecoverFrames is like Recover, but it also passes the frame of the function
+// whose deferred call recovered the error, which may differ from
+// the frame in which the error occurred if the E function was called
+// by a helper function.
+//
+// The handler frame is determined by locating on the stack the innermost
+// function whose source encloses the declaration of the function literal fn,
+// using the positions recorded for the program counters of the stack
+// at the time the deferred handler runs. If fn is not a function literal
+// declared in the function that defers the call to RecoverFrames,
+// then the handler frame is the zero frame.
+//
+//	func serve() {
+//		defer try.RecoverFrames(func(err error, site, handler runtime.Frame) {
+//			log.Printf("%s:%d: error caught by %s: %v", site.File, site.Line, handler.Function, err)
+//		})
+//		...
+//	}
+func RecoverFrames(fn func(err error, site, handler runtime.Frame)) {
+	r(recover(), func(w *Error) { fn(w.err, w.Frame(), handlerOf(w, fn)) })
+}
+
+// Frame is a program counter of a recorded call as reported by runtime.Callers.
+// Together with StackTrace, it mirrors the types of the same name
+// in github.com/pkg/errors so that tools that inspect stack traces
+// by their shape, such as error reporting services, recognize them.
+type Frame uintptr
+
+// frame returns the runtime frame for f.
+func (f Frame) frame() runtime.Frame {
+	frame, _ := runtime.CallersFrames([]uintptr{uintptr(f)}).Next()
+	return frame
+}
+
+// Format implements fmt.Formatter.
+// The %s verb prints the file name, %d the line, %n the short function name,
+// and %v the file name and line separated by a colon.
+// The %+v verb prints the function name and the full file path and line
+// separated by a newline and a tab.
+func (f Frame) Format(s fmt.State, verb rune) {
+	frame := f.frame()
+	switch verb {
+	case 's':
+		io.WriteString(s, lastSegment(frame.File))
+	case 'd':
+		io.WriteString(s, strconv.Itoa(frame.Line))
+	case 'n':
+		io.WriteString(s, lastSegment(frame.Function))
+	case 'v':
+		if s.Flag('+') {
+			io.WriteString(s, frame.Function+"\n\t"+frame.File+":"+strconv.Itoa(frame.Line))
+		} else {
+			io.WriteString(s, lastSegment(frame.File)+":"+strconv.Itoa(frame.Line))
+		}
+	}
+}
+
+// StackTrace is a stack of frames starting at the frame in which an error occurred.
+type StackTrace []Frame
+
+// Format implements fmt.Formatter.
+// The %v verb prints the frames as a list, while
+// the %+v verb prints each frame formatted with %+v on its own lines.
+func (st StackTrace) Format(s fmt.State, verb rune) {
+	if verb != 'v' {
+		return
+	}
+	if s.Flag('+') {
+		for _, f := range st {
+			fmt.Fprintf(s, "\n%+v", f)
+		}
+		return
+	}
+	io.WriteString(s, "[")
+	for i, f := range st {
+		if i > 0 {
+			io.WriteString(s, " ")
+		}
+		f.Format(s, 'v')
+	}
+	io.WriteString(s, "]")
+}
+
+// StackTrace returns the program counters recorded when the error occurred.
+// Unless SetStackDepth was called with a depth greater than 1,
+// only the call in which the error occurred is recorded.
+func (e *Error) StackTrace() StackTrace {
+	st := make(StackTrace, len(e.pcs))
+	for i, pc := range e.pcs {
+		st[i] = Frame(pc)
+	}
+	return st
+}
+
+// maxSites is the maximum number of sites recorded for an error.
+const maxSites = 8
+
+// accumulateSites returns the sites of an error panicked at pc,
+// which are pc followed by the sites of any *Error in err's tree,
+// or nil if there is no such *Error.
+func accumulateSites(pc uintptr, err error) []uintptr {
+	var inner *Error
+	if !errors.As(err, &inner) {
+		return nil
+	}
+	sites := append([]uintptr{pc}, inner.sitePCs()...)
+	if len(sites) > maxSites {
+		// Always retain the original site.
+		sites = append(sites[:maxSites-1], sites[len(sites)-1])
+	}
+	return sites
+}
+
+// sitePCs returns the program counters of the calls in which the error was panicked.
+func (e *Error) sitePCs() []uintptr {
+	if e.sites != nil {
+		return e.sites
+	}
+	return e.pcs[:1]
+}
+
+// Sites returns the frames in which the error was panicked, newest first.
+// When an E function panics with an error that already contains an *Error,
+// such as one stored by HandleAny or passed to the function of F
+// and then returned to a caller, the sites of the earlier error are retained
+// so that the original site is not lost as the error travels up
+// through functions using this package.
+// At most 8 sites are retained, which always include the original site.
+func (e *Error) Sites() []runtime.Frame {
+	pcs := e.sitePCs()
+	sites := make([]runtime.Frame, len(pcs))
+	for i, pc := range pcs {
+		sites[i], _ = runtime.CallersFrames([]uintptr{pc}).Next()
+	}
+	return sites
+}
+
+// SetPreserveOrigin sets whether the E functions retain the origin of an error
+// that already carries the location in which it occurred,
+// rather than recording the location of the E call.
+// This allows an error that surfaces through several functions using this
+// package to keep its original frame rather than being relabeled at each boundary.
+//
+// An error carries its origin if it or an error in its chain of
+// single Unwrap methods is an *Error, has a StackTrace method that returns
+// a StackTrace, or has a Callers method that returns a slice of program counters.
+// An error that is an *Error is unwrapped so that its location
+// is not rendered twice.
+// It is intended to be called during program initialization.
+func SetPreserveOrigin(enable bool) {
+	preserveOrigin.Store(enable)
+}
+
+// originOf returns the error to panic with and the recorded program counters
+// if err carries its origin, otherwise it returns nil program counters.
+func originOf(err error) (error, []uintptr) {
+	if e, ok := err.(*Error); ok {
+		return e.err, e.pcs
+	}
+	for inner := err; inner != nil; inner = errors.Unwrap(inner) {
+		if e, ok := inner.(*Error); ok {
+			return err, e.pcs
+		}
+		if pcs := stackTraceOf(inner); pcs != nil {
+			return err, pcs
+		}
+	}
+	return err, nil
+}
+
+// stackTraceOf returns the program counters reported by the StackTrace
+// or Callers method of err, or nil if err has neither method.
+func stackTraceOf(err error) []uintptr {
+	switch err := err.(type) {
+	case interface{ StackTrace() StackTrace }:
+		st := err.StackTrace()
+		if len(st) == 0 {
+			return nil
+		}
+		pcs := make([]uintptr, len(st))
+		for i, f := range st {
+			pcs[i] = uintptr(f)
+		}
+		return pcs
+	case interface{ Callers() []uintptr }:
+		if pcs := err.Callers(); len(pcs) > 0 {
+			return pcs
+		}
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/dsnet/try"
+)
+
+func TestHandleStack(t *testing.T) {
+	var stack []runtime.Frame
+	err := func() (err error) {
+		defer try.HandleStack(&err, func(s []runtime.Frame) { stack = s })
+		stackMiddle()
+		return nil
+	}()
+	if err != io.EOF {
+		t.Errorf("HandleStack: got %v, want %v", err, io.EOF)
+	}
+	if len(stack) < 3 {
+		t.Fatalf("len(stack) = %d, want at least 3", len(stack))
+	}
+	if filepath.Base(stack[0].File) != "leaf.go" || stack[0].Line != 4 {
+		t.Errorf("stack[0] = %s:%d, want leaf.go:4", stack[0].File, stack[0].Line)
+	}
+	for i, want := range []string{".stackLeaf", ".stackMiddle", ".TestHandleStack.func"} {
+		if !strings.Contains(stack[i].Function, want) {
+			t.Errorf("stack[%d].Function = %q, want it to contain %q", i, stack[i].Function, want)
+		}
+	}
+	for _, frame := range stack {
+		if strings.HasPrefix(frame.Function, "runtime.") {
+			t.Errorf("stack contains runtime frame %q", frame.Function)
+		}
+	}
+}
+
+func TestRecoverFrames(t *testing.T) {
+	var site, handler runtime.Frame
+	func() {
+		defer try.RecoverFrames(func(err error, s, h runtime.Frame) { site, handler = s, h })
+//line handler.go:8
+		stackMiddle()
+	}()
+	if filepath.Base(site.File) != "leaf.go" || site.Line != 4 {
+		t.Errorf("site = %s:%d, want leaf.go:4", site.File, site.Line)
+	}
+	if filepath.Base(handler.File) != "handler.go" || handler.Line != 8 {
+		t.Errorf("handler = %s:%d, want handler.go:8", handler.File, handler.Line)
+	}
+	if !strings.HasSuffix(handler.Function, ".TestRecoverFrames.func1") {
+		t.Errorf("handler.Function = %q, want suffix %q", handler.Function, ".TestRecoverFrames.func1")
+	}
+
+	// A function literal declared elsewhere has no known handler frame.
+	handler = runtime.Frame{Line: -1}
+	func() {
+		defer try.RecoverFrames(recordHandler(&handler))
+		stackMiddle()
+	}()
+	if handler != (runtime.Frame{}) {
+		t.Errorf("handler = %v, want zero frame", handler)
+	}
+
+	// A function literal declared earlier and still on the stack
+	// is not mistaken for the handler frame.
+	func() {
+		g := func() { stackMiddle() }
+		defer try.RecoverFrames(func(err error, s, h runtime.Frame) { handler = h })
+//line sibling.go:20
+		g()
+	}()
+	if filepath.Base(handler.File) != "sibling.go" || handler.Line != 20 {
+		t.Errorf("handler = %s:%d, want sibling.go:20", handler.File, handler.Line)
+	}
+}
+
+func recordHandler(handler *runtime.Frame) func(error, runtime.Frame, runtime.Frame) {
+	return func(err error, site, h runtime.Frame) { *handler = h }
+}
+
+func TestSetStackDepth(t *testing.T) {
+	record := func() (e *try.Error) {
+		defer try.F(func(args ...any) { e = args[0].(*try.Error) })
+		stackMiddle()
+		return nil
+	}
+
+	if stack := record().Stack(); len(stack) != 1 || filepath.Base(stack[0].File) != "leaf.go" {
+		t.Errorf("default Stack() = %v, want only leaf.go frame", stack)
+	}
+
+	try.SetStackDepth(3)
+	defer try.SetStackDepth(1)
+	stack := record().Stack()
+	if len(stack) != 3 {
+		t.Fatalf("len(Stack()) = %d, want 3", len(stack))
+	}
+	for i, want := range []string{".stackLeaf", ".stackMiddle", ".TestSetStackDepth.func"} {
+		if !strings.Contains(stack[i].Function, want) {
+			t.Errorf("stack[%d].Function = %q, want it to contain %q", i, stack[i].Function, want)
+		}
+	}
+}
+
+func TestStackTrace(t *testing.T) {
+	try.SetStackDepth(2)
+	defer try.SetStackDepth(1)
+	e := recordError(stackMiddle)
+
+	st := e.StackTrace()
+	if len(st) != 2 {
+		t.Fatalf("len(StackTrace()) = %d, want 2", len(st))
+	}
+	tests := []struct {
+		format string
+		value  any
+		want   string
+	}{
+		{"%s", st[0], "leaf.go"},
+		{"%d", st[0], "4"},
+		{"%n", st[0], "try_test.stackLeaf"},
+		{"%v", st[0], "leaf.go:4"},
+		{"%+v", st[0], "github.com/dsnet/try_test.stackLeaf\n\t.*leaf.go:4"},
+		{"%v", st, `\[leaf.go:4 \w+.go:\d+\]`},
+		{"%+v", st, `\n.*stackLeaf\n\t.*leaf.go:4\n.*stackMiddle\n\t.*\.go:\d+`},
+	}
+	for _, tt := range tests {
+		got := fmt.Sprintf(tt.format, tt.value)
+		if !regexp.MustCompile("^" + tt.want + "$").MatchString(got) {
+			t.Errorf("Sprintf(%q) = %q, want match of %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+// tracedError is an error with a stack trace in the shape of github.com/pkg/errors.
+type tracedError struct{ st try.StackTrace }
+
+func (e tracedError) Error() string              { return "traced" }
+func (e tracedError) StackTrace() try.StackTrace { return e.st }
+
+// callersError is an error that reports its program counters with Callers.
+type callersError struct{ pcs []uintptr }
+
+func (e callersError) Error() string      { return "callers" }
+func (e callersError) Callers() []uintptr { return e.pcs }
+
+func TestSetPreserveOrigin(t *testing.T) {
+	orig := recordError(stackMiddle)
+	rethrow := func(err error) *try.Error {
+		return recordError(func() {
+//line rethrow.go:8
+			try.E(err)
+		})
+	}
+	var pcs []uintptr
+	for _, f := range orig.StackTrace() {
+		pcs = append(pcs, uintptr(f))
+	}
+	tests := []struct {
+		in         error
+		wantUnwrap error
+	}{
+		{in: orig, wantUnwrap: io.EOF},
+		{in: fmt.Errorf("wrapped: %w", orig)},
+		{in: &tracedError{orig.StackTrace()}},
+		{in: &callersError{pcs}},
+	}
+
+	for _, tt := range tests {
+		if got := filepath.Base(rethrow(tt.in).Frame().File); got != "rethrow.go" {
+			t.Errorf("E(%v) frame file = %q, want rethrow.go", tt.in, got)
+		}
+	}
+
+	try.SetPreserveOrigin(true)
+	defer try.SetPreserveOrigin(false)
+	for _, tt := range tests {
+		e := rethrow(tt.in)
+		if got := filepath.Base(e.Frame().File); got != "leaf.go" {
+			t.Errorf("E(%v) frame file = %q, want leaf.go", tt.in, got)
+		}
+		want := tt.wantUnwrap
+		if want == nil {
+			want = tt.in
+		}
+		if e.Unwrap() != want {
+			t.Errorf("E(%v).Unwrap() = %v, want %v", tt.in, e.Unwrap(), want)
+		}
+	}
+	if got := filepath.Base(rethrow(io.EOF).Frame().File); got != "rethrow.go" {
+		t.Errorf("E(io.EOF) frame file = %q, want rethrow.go", got)
+	}
+}
+
+func TestSites(t *testing.T) {
+	layer := func(line int, err error) (out error) {
+		defer try.HandleAny(&out)
+		switch line {
+//line layer.go:1
+		case 1:
+			try.E(err)
+		case 2:
+			try.E(err)
+		case 3:
+			try.E(err)
+		}
+		return nil
+	}
+	err := layer(1, io.EOF)
+	err = layer(2, err)
+	err = layer(3, fmt.Errorf("wrapped: %w", err))
+
+	var e *try.Error
+	if !errors.As(err, &e) {
+		t.Fatalf("errors.As(%v, *try.Error) = false, want true", err)
+	}
+	var got []int
+	for _, site := range e.Sites() {
+		got = append(got, site.Line)
+	}
+	if want := []int{6, 4, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Sites() lines = %v, want %v", got, want)
+	}
+
+	// Only the most recent sites and the original site are retained.
+	err = io.EOF
+	for i := 0; i < 10; i++ {
+		err = layer(1+i%3, err)
+	}
+	errors.As(err, &e)
+	got = nil
+	for _, site := range e.Sites() {
+		got = append(got, site.Line)
+	}
+	if want := []int{2, 6, 4, 2, 6, 4, 2, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Sites() lines = %v, want %v", got, want)
+	}
+}
+
+//go:noinline
+func stackMiddle() {
+	stackLeaf()
+}
+
+//go:noinline
+func stackLeaf() {
+//line leaf.go:4
+	try.E(io.EOF)
+}
@@ -0,0 +1,57 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try
+
+// TB is the subset of testing.TB used by the test handlers.
+type TB interface {
+	Helper()
+	Fatal(args ...any)
+	Skip(args ...any)
+}
+
+// HandleTB recovers an error previously panicked with an E function
+// and fails the test with it.
+// The failure message includes the file and line in which the error occurred.
+//
+//	func TestFoo(t *testing.T) {
+//		defer try.HandleTB(t)
+//		...
+//	}
+func HandleTB(tb TB) {
+	tb.Helper()
+	// Avoid r so that tb.Fatal is called directly from a helper function.
+	switch ex := recover().(type) {
+	case nil:
+	case *Error:
+		defer notify(ex)
+		tb.Fatal(ex)
+	default:
+		panic(ex)
+	}
+}
+
+// HandleSkipTB is like HandleTB, but skips the test if the recovered error
+// matches any of targets according to errors.Is.
+//
+//	func TestDatabase(t *testing.T) {
+//		defer try.HandleSkipTB(t, errMissingCredentials)
+//		...
+//	}
+func HandleSkipTB(tb TB, targets ...error) {
+	tb.Helper()
+	// Avoid r so that tb.Skip and tb.Fatal are called directly from a helper function.
+	switch ex := recover().(type) {
+	case nil:
+	case *Error:
+		defer notify(ex)
+		if isAny(ex.err, targets) {
+			tb.Skip(ex)
+		} else {
+			tb.Fatal(ex)
+		}
+	default:
+		panic(ex)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/dsnet/try"
+)
+
+type fakeTB struct {
+	helpers int
+	fatal   string
+	skip    string
+}
+
+func (tb *fakeTB) Helper()           { tb.helpers++ }
+func (tb *fakeTB) Fatal(args ...any) { tb.fatal = fmt.Sprint(args...) }
+func (tb *fakeTB) Skip(args ...any)  { tb.skip = fmt.Sprint(args...) }
+
+func TestHandleTB(t *testing.T) {
+	tb := new(fakeTB)
+	func() {
+		defer try.HandleTB(tb)
+//line /full/path/to/x_test.go:4
+		try.E(io.EOF)
+	}()
+	if tb.helpers == 0 {
+		t.Errorf("HandleTB did not call Helper")
+	}
+	if want := "x_test.go:4: try_test.TestHandleTB.func1: EOF"; try.TrimDebug(tb.fatal) != want {
+		t.Errorf("HandleTB failed with %q, want %q", tb.fatal, want)
+	}
+}
+
+func TestHandleSkipTB(t *testing.T) {
+	errUnsupported := errors.New("unsupported platform")
+	tests := []struct {
+		in        error
+		wantSkip  bool
+		wantFatal bool
+	}{
+		{in: nil},
+		{in: errUnsupported, wantSkip: true},
+		{in: io.EOF, wantFatal: true},
+	}
+	for _, tt := range tests {
+		tb := new(fakeTB)
+		func() {
+			defer try.HandleSkipTB(tb, errUnsupported)
+			try.E(tt.in)
+		}()
+		if gotSkip := tb.skip != ""; gotSkip != tt.wantSkip {
+			t.Errorf("HandleSkipTB(%v) skipped = %v, want %v", tt.in, gotSkip, tt.wantSkip)
+		}
+		if gotFatal := tb.fatal != ""; gotFatal != tt.wantFatal {
+			t.Errorf("HandleSkipTB(%v) failed = %v, want %v", tt.in, gotFatal, tt.wantFatal)
+		}
+	}
+}
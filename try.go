@@ -70,10 +70,15 @@
 //		return nil
 //	}
 //
-// Quick tour of the API
+// # Quick tour of the API
 //
 // The E family of functions all remove a final error return, panicking if non-nil.
 //
+// Ef is like E, but it wraps the error with a formatted message,
+// which is useful when a function has many E calls that each need their own context.
+//
+//	try.Ef(os.WriteFile(path, b, 0664), "writing config %q: %w", path)
+//
 // Handle recovers from that panic and allows assignment of the error to a return
 // error value. Other panics are not recovered.
 //
@@ -122,71 +127,183 @@
 //		})
 //		...
 //	}
+//
+// When multiple handlers are deferred in one function, only the last deferred,
+// which runs first, recovers the error. Rethrow calls a function with the error
+// and panics again so that handlers can be layered.
+//
+//	func f() (err error) {
+//		defer try.Handle(&err)
+//		defer try.Rethrow(func(err error, frame runtime.Frame) {
+//			log.Printf("%s:%d: %v", frame.File, frame.Line, err)
+//		})
+//		...
+//	}
+//
+// The T family of functions pack values and a final error into a tuple,
+// which can be stored or passed around and later unwrapped with its E method.
+//
+//	res := try.T2(Buzz(...))
+//	...
+//	a, b := res.E()
+//
+// The Must family of functions are like the E family, but panic with the error as is.
+// They are intended for package initialization, where no handler can run.
+//
+//	var tmpl = try.Must1(template.ParseFS(files, "*.tmpl"))
+//
+// Building with the trydebug build tag enables diagnostics that are too
+// expensive for normal use, such as recording the goroutine in which an error occurred.
 package try
 
 import (
+	"errors"
 	"runtime"
 	"strconv"
+	"strings"
+	"sync"
 )
 
-// wrapError wraps an error to ensure that we only recover from errors
+// Error is an error panicked by the E functions together with
+// the location in which it occurred.
+// It wraps the error to ensure that handlers only recover from errors
 // panicked by this package.
-type wrapError struct {
-	error
-	pc [1]uintptr
+//
+// Handlers store the underlying error without the location,
+// while F passes the *Error to its function so that the location
+// is included in the message. The location can be retrieved
+// from such errors with errors.As:
+//
+//	var e *try.Error
+//	if errors.As(err, &e) {
+//		frame := e.Frame()
+//		...
+//	}
+type Error struct {
+	err error
+	pcs []uintptr // pcs[0] is the call in which the error occurred
+
+	// sites are the calls in which the error was previously panicked,
+	// starting with pcs[0]. It is nil if there are no earlier sites.
+	sites []uintptr
+
+	// The frame is only symbolized when first requested,
+	// so that errors which are simply stored or discarded remain cheap.
+	once  sync.Once
+	frame runtime.Frame
+
+	goid uint64 // only recorded when built with the trydebug build tag
 }
 
-func (e wrapError) Error() string {
-	// Retrieve the last path segment of the filename.
-	// We avoid using strings.LastIndexByte to keep dependencies small.
-	frames := runtime.CallersFrames(e.pc[:])
-	frame, _ := frames.Next()
-	file := frame.File
-	for i := len(file) - 1; i >= 0; i-- {
-		if file[i] == '/' {
-			file = file[i+len("/"):]
-			break
-		}
+// Frame returns the runtime frame in which the error occurred.
+func (e *Error) Frame() runtime.Frame {
+	e.once.Do(func() { e.frame, _ = runtime.CallersFrames(e.pcs[:1]).Next() })
+	return e.frame
+}
+
+// PC returns the program counter of the call in which the error occurred,
+// as reported by runtime.Callers.
+func (e *Error) PC() uintptr {
+	return e.pcs[0]
+}
+
+// Error returns the message of the underlying error prefixed with
+// the file, line, and short function name in which it occurred
+// (e.g., "internal/parser/lex.go:87: parser.(*Lexer).next: unexpected EOF").
+// The file is relative to the root of its module if the module is known,
+// and otherwise is only the last path segment (see also SetWorkdirPaths).
+// The rendering can be changed with SetFormatter and SetRedactor.
+// When built with the trydebug build tag, it is followed by the
+// identifier of the goroutine in which the error occurred
+// (e.g., "[goroutine 7]"), and if the error is crashing the program
+// because no handler recovered it, by an explanation that identifies
+// the go statement that started the goroutine, which is where a handler
+// is likely missing. Deferred handlers themselves are not tracked.
+func (e *Error) Error() string {
+	var s string
+	if fn := formatter.Load(); fn != nil {
+		s = (*fn)(e.err, e.Frame())
+	} else {
+		s = formatError(e.err, e.Frame())
+	}
+	if debugMode {
+		s += " [goroutine " + strconv.FormatUint(e.goid, 10) + "]" + crashHint(e)
 	}
-	return file + ":" + strconv.Itoa(frame.Line) + ": " + e.error.Error()
+	return redact(s)
 }
 
-// Unwrap primarily exists for testing purposes.
-func (e wrapError) Unwrap() error {
-	return e.error
+// formatError is the default rendering of an error that occurred in frame.
+func formatError(err error, frame runtime.Frame) string {
+	s := displayFile(frame) + ":" + strconv.Itoa(frame.Line) + ": "
+	if frame.Function != "" {
+		s += lastSegment(frame.Function) + ": "
+	}
+	return s + err.Error()
+}
+
+// lastSegment retrieves the last segment of a slash-separated path,
+// which for a function name is the package name and the function name.
+func lastSegment(s string) string {
+	return s[strings.LastIndexByte(s, '/')+len("/"):]
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// ErrRecovered matches any *Error with errors.Is, which allows
+// distinguishing errors that were panicked by an E function and therefore
+// carry the location in which they occurred from ordinary errors.
+// Note that most handlers store the underlying error without the location.
+var ErrRecovered = errors.New("try: recovered error")
+
+// Is reports whether target is ErrRecovered.
+func (e *Error) Is(target error) bool {
+	return target == ErrRecovered
 }
 
-func r(recovered any, fn func(wrapError)) {
+func r(recovered any, fn func(*Error)) {
 	switch ex := recovered.(type) {
 	case nil:
-	case wrapError:
+	case *Error:
+		defer notify(ex)
 		fn(ex)
 	default:
 		panic(ex)
 	}
 }
 
+// checkErrptr panics with a descriptive message if errptr is nil.
+// Otherwise, storing the recovered error would result in a nil pointer
+// dereference that masks the error.
+func checkErrptr(errptr *error, err error) {
+	if errptr == nil {
+		panic("try: handler called with nil error pointer while recovering error: " + err.Error())
+	}
+}
+
 // Recover recovers an error previously panicked with an E function.
 // If it recovers an error, it calls fn with the error and the runtime frame in which it occurred.
 func Recover(fn func(err error, frame runtime.Frame)) {
-	r(recover(), func(w wrapError) {
-		frames := runtime.CallersFrames(w.pc[:])
-		frame, _ := frames.Next()
-		fn(w.error, frame)
-	})
+	r(recover(), func(w *Error) { fn(w.err, w.Frame()) })
 }
 
 // Handle recovers an error previously panicked with an E function and stores it into errptr.
 func Handle(errptr *error) {
-	r(recover(), func(w wrapError) { *errptr = w.error })
+	r(recover(), func(w *Error) {
+		checkErrptr(errptr, w)
+		*errptr = w.err
+	})
 }
 
 // HandleF recovers an error previously panicked with an E function and stores it into errptr.
 // If it recovers an error, it calls fn.
 func HandleF(errptr *error, fn func()) {
-	r(recover(), func(w wrapError) {
-		*errptr = w.error
-		if w.error != nil {
+	r(recover(), func(w *Error) {
+		checkErrptr(errptr, w)
+		*errptr = w.err
+		if w.err != nil {
 			fn()
 		}
 	})
@@ -196,20 +313,49 @@ func HandleF(errptr *error, fn func()) {
 // The wrapping includes the file and line of the runtime frame in which it occurred.
 // F pairs well with testing.TB.Fatal and log.Fatal.
 func F(fn func(...any)) {
-	r(recover(), func(w wrapError) { f(fn, w) })
+	r(recover(), func(w *Error) { f(fn, w) })
 }
 
-func e(err error) {
-	we := wrapError{error: err}
-	// 3: runtime.Callers, e, E
-	runtime.Callers(3, we.pc[:])
-	panic(we)
+// wrap wraps err with the frame that is skip frames above the caller of wrap.
+// If SetPreserveOrigin is enabled and err already carries its origin,
+// the origin is retained instead.
+func wrap(skip int, err error) *Error {
+	e := &Error{err: err}
+	if preserveOrigin.Load() {
+		e.err, e.pcs = originOf(err)
+	}
+	if e.pcs == nil {
+		e.pcs = make([]uintptr, stackDepth())
+		// 2: runtime.Callers, wrap
+		n := runtime.Callers(2+skip, e.pcs)
+		e.pcs = e.pcs[:n]
+		e.sites = accumulateSites(e.pcs[0], err)
+	}
+	if debugMode {
+		e.goid = goid()
+	}
+	return e
+}
+
+func throw(err error) {
+	// 2: throw, E
+	panic(wrap(2, err))
 }
 
 // E panics if err is non-nil.
 func E(err error) {
 	if err != nil {
-		e(err)
+		throw(err)
+	}
+}
+
+// ESkip is like E, but records the frame that is skip frames above the caller.
+// It allows helper functions to attribute an error to their own callers.
+// ESkip(0, err) is equivalent to E(err).
+func ESkip(skip int, err error) {
+	if err != nil {
+		// 1: ESkip
+		panic(wrap(1+skip, err))
 	}
 }
 
@@ -217,7 +363,7 @@ func E(err error) {
 // It panics if err is non-nil.
 func E1[A any](a A, err error) A {
 	if err != nil {
-		e(err)
+		throw(err)
 	}
 	return a
 }
@@ -226,7 +372,7 @@ func E1[A any](a A, err error) A {
 // It panics if err is non-nil.
 func E2[A, B any](a A, b B, err error) (A, B) {
 	if err != nil {
-		e(err)
+		throw(err)
 	}
 	return a, b
 }
@@ -235,7 +381,7 @@ func E2[A, B any](a A, b B, err error) (A, B) {
 // It panics if err is non-nil.
 func E3[A, B, C any](a A, b B, c C, err error) (A, B, C) {
 	if err != nil {
-		e(err)
+		throw(err)
 	}
 	return a, b, c
 }
@@ -244,17 +390,53 @@ func E3[A, B, C any](a A, b B, c C, err error) (A, B, C) {
 // It panics if err is non-nil.
 func E4[A, B, C, D any](a A, b B, c C, d D, err error) (A, B, C, D) {
 	if err != nil {
-		e(err)
+		throw(err)
 	}
 	return a, b, c, d
 }
 
+// E5 returns a, b, c, d, and e as is.
+// It panics if err is non-nil.
+func E5[A, B, C, D, E any](a A, b B, c C, d D, e E, err error) (A, B, C, D, E) {
+	if err != nil {
+		throw(err)
+	}
+	return a, b, c, d, e
+}
+
+// E6 returns a, b, c, d, e, and f as is.
+// It panics if err is non-nil.
+func E6[A, B, C, D, E, F any](a A, b B, c C, d D, e E, f F, err error) (A, B, C, D, E, F) {
+	if err != nil {
+		throw(err)
+	}
+	return a, b, c, d, e, f
+}
+
+// E7 returns a, b, c, d, e, f, and g as is.
+// It panics if err is non-nil.
+func E7[A, B, C, D, E, F, G any](a A, b B, c C, d D, e E, f F, g G, err error) (A, B, C, D, E, F, G) {
+	if err != nil {
+		throw(err)
+	}
+	return a, b, c, d, e, f, g
+}
+
+// E8 returns a, b, c, d, e, f, g, and h as is.
+// It panics if err is non-nil.
+func E8[A, B, C, D, E, F, G, H any](a A, b B, c C, d D, e E, f F, g G, h H, err error) (A, B, C, D, E, F, G, H) {
+	if err != nil {
+		throw(err)
+	}
+	return a, b, c, d, e, f, g, h
+}
+
 // f simply calls fn with w.
 //
 // This uses the special "line" pragma to set the file and line number to be
 // something consistent. It must be declared last in the file to prevent "line"
 // from affecting the line numbers of anything else in this file.
-func f(fn func(...any), w wrapError) {
+func f(fn func(...any), w *Error) {
 //line try.go:1
 	fn(w)
 }
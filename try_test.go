@@ -6,8 +6,10 @@ package try_test
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"log"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
@@ -114,7 +116,7 @@ func Test(t *testing.T) {
 func TestFrame(t *testing.T) {
 	t.Run("E", func(t *testing.T) {
 		defer try.Recover(func(err error, frame runtime.Frame) {
-			if frame.File != "x.go" {
+			if filepath.Base(frame.File) != "x.go" {
 				t.Errorf("want File=x.go, got %q", frame.File)
 			}
 			if frame.Line != 4 {
@@ -126,7 +128,7 @@ func TestFrame(t *testing.T) {
 	})
 	t.Run("E3", func(t *testing.T) {
 		defer try.Recover(func(err error, frame runtime.Frame) {
-			if frame.File != "x.go" {
+			if filepath.Base(frame.File) != "x.go" {
 				t.Errorf("want File=x.go, got %q", frame.File)
 			}
 			if frame.Line != 4 {
@@ -136,14 +138,26 @@ func TestFrame(t *testing.T) {
 //line x.go:4
 		try.E3(failure())
 	})
+	t.Run("E8", func(t *testing.T) {
+		defer try.Recover(func(err error, frame runtime.Frame) {
+			if filepath.Base(frame.File) != "x.go" {
+				t.Errorf("want File=x.go, got %q", frame.File)
+			}
+			if frame.Line != 4 {
+				t.Errorf("want Line=4, got %d", frame.Line)
+			}
+		})
+//line x.go:4
+		try.E8(wideFailure())
+	})
 }
 
 func TestF(t *testing.T) {
 	buf := new(strings.Builder)
 	logger := log.New(buf, "", log.Lshortfile)
 	defer func() {
-		const want = "try.go:1: y.go:10: EOF\n"
-		if got := buf.String(); got != want {
+		const want = "try.go:1: y.go:10: try_test.TestF: EOF\n"
+		if got := try.TrimDebug(buf.String()); got != want {
 			t.Errorf("want %q, got %q", want, got)
 		}
 	}()
@@ -162,6 +176,45 @@ func TestHandleOverwrite(t *testing.T) {
 	}
 }
 
+func TestHandleNil(t *testing.T) {
+	tests := map[string]func(){
+		"Handle": func() {
+			defer try.Handle(nil)
+//line x.go:4
+			try.E(io.EOF)
+		},
+		"HandleF": func() {
+			defer try.HandleF(nil, func() {})
+//line x.go:4
+			try.E(io.EOF)
+		},
+		"HandleAny": func() {
+			defer try.HandleAny(nil)
+//line x.go:4
+			try.E(io.EOF)
+		},
+		"HandleDeferred": func() {
+			defer try.HandleDeferred(nil, func() error { return nil })
+//line x.go:4
+			try.E(io.EOF)
+		},
+	}
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got any
+			func() {
+				defer func() { got = recover() }()
+				run()
+			}()
+			const wantPrefix = "try: handler called with nil error pointer while recovering error: x.go:4: try_test.TestHandleNil.func"
+			const wantSuffix = ": EOF"
+			if s, _ := got.(string); !strings.HasPrefix(s, wantPrefix) || !strings.HasSuffix(try.TrimDebug(s), wantSuffix) {
+				t.Errorf("recovered %v, want %v...%v", got, wantPrefix, wantSuffix)
+			}
+		})
+	}
+}
+
 func success() (a int, b string, c bool, err error) {
 	return +1, "success", true, nil
 }
@@ -170,6 +223,10 @@ func failure() (a int, b string, c bool, err error) {
 	return -1, "failure", false, io.EOF
 }
 
+func wideFailure() (a, b, c, d, e, f, g, h int, err error) {
+	return 1, 2, 3, 4, 5, 6, 7, 8, io.EOF
+}
+
 var sink struct {
 	A int
 	B string
@@ -204,3 +261,77 @@ func BenchmarkFailure(b *testing.B) {
 		}()
 	}
 }
+
+func TestESkip(t *testing.T) {
+	helper := func(err error) {
+//line helper.go:1
+		try.ESkip(1, err)
+	}
+	defer try.Recover(func(err error, frame runtime.Frame) {
+		if filepath.Base(frame.File) != "x.go" {
+			t.Errorf("want File=x.go, got %q", frame.File)
+		}
+		if frame.Line != 4 {
+			t.Errorf("want Line=4, got %d", frame.Line)
+		}
+	})
+	try.ESkip(0, nil)
+//line x.go:4
+	helper(io.EOF)
+}
+
+func TestError(t *testing.T) {
+	var got error
+	func() {
+		defer try.F(func(args ...any) { got = args[0].(error) })
+//line /full/path/to/x.go:4
+		try.E(io.EOF)
+	}()
+	var e *try.Error
+	if !errors.As(got, &e) {
+		t.Fatalf("errors.As(%v, *try.Error) = false, want true", got)
+	}
+	if frame := e.Frame(); filepath.Base(frame.File) != "x.go" || frame.Line != 4 {
+		t.Errorf("Frame() = %s:%d, want x.go:4", frame.File, frame.Line)
+	}
+	if frame, _ := runtime.CallersFrames([]uintptr{e.PC()}).Next(); frame != e.Frame() {
+		t.Errorf("frame of PC() = %v, want %v", frame, e.Frame())
+	}
+	if e.Unwrap() != io.EOF {
+		t.Errorf("Unwrap() = %v, want %v", e.Unwrap(), io.EOF)
+	}
+	if want := "x.go:4: try_test.TestError.func1: EOF"; try.TrimDebug(e.Error()) != want {
+		t.Errorf("Error() = %q, want %q", e.Error(), want)
+	}
+}
+
+func TestErrorFrameConcurrent(t *testing.T) {
+	e := recordError(func() {
+//line x.go:4
+		try.E(io.EOF)
+	})
+	frames := make(chan runtime.Frame, 4)
+	for i := 0; i < cap(frames); i++ {
+		go func() { frames <- e.Frame() }()
+	}
+	for i := 0; i < cap(frames); i++ {
+		if frame := <-frames; filepath.Base(frame.File) != "x.go" || frame.Line != 4 {
+			t.Errorf("Frame() = %s:%d, want x.go:4", frame.File, frame.Line)
+		}
+	}
+}
+
+func TestErrRecovered(t *testing.T) {
+	e := recordError(func() { try.E(io.EOF) })
+	for _, err := range []error{e, fmt.Errorf("wrapped: %w", e)} {
+		if !errors.Is(err, try.ErrRecovered) {
+			t.Errorf("errors.Is(%v, ErrRecovered) = false, want true", err)
+		}
+		if !errors.Is(err, io.EOF) {
+			t.Errorf("errors.Is(%v, io.EOF) = false, want true", err)
+		}
+	}
+	if errors.Is(io.EOF, try.ErrRecovered) {
+		t.Errorf("errors.Is(io.EOF, ErrRecovered) = true, want false")
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+// Command trystats reports statistics on the use of package try.
+//
+// Usage:
+//
+//	trystats [flags] [packages]
+//
+// For each package, it reports the number of calls to E functions,
+// the number of deferred handlers, the number of functions that mix
+// calls to E functions with explicitly returned errors, and the number
+// of places where panics from E functions may escape, as reported by
+// the unhandled, goroutine, and leak analyzers of package trycheck.
+// It then reports the totals and the number of uses of each handler.
+// The packages default to "./...".
+//
+// The flags are:
+//
+//	-json
+//		Print the statistics of each package as JSON.
+//	-v
+//		List the mixing functions and escapes of each package.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/dsnet/try/trycheck"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+)
+
+// escapers are the analyzers that report where panics may escape.
+var escapers = []*analysis.Analyzer{trycheck.Unhandled, trycheck.Goroutine, trycheck.Leak}
+
+// stats are the statistics for a single package.
+type stats struct {
+	Package string `json:"package"`
+	trycheck.Usage
+	Escapes []string `json:"escapes,omitempty"`
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("trystats: ")
+
+	asJSON := flag.Bool("json", false, "print the statistics of each package as JSON")
+	verbose := flag.Bool("v", false, "list the mixing functions and escapes of each package")
+	flag.Parse()
+
+	patterns := flag.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	all, err := collect(&packages.Config{}, patterns...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *asJSON {
+		e := json.NewEncoder(os.Stdout)
+		e.SetIndent("", "\t")
+		if err := e.Encode(all); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	print(os.Stdout, all, *verbose)
+}
+
+// collect loads the packages matching patterns and computes their statistics.
+func collect(cfg *packages.Config, patterns ...string) ([]stats, error) {
+	cfg.Mode = packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedSyntax |
+		packages.NeedTypes | packages.NeedTypesInfo | packages.NeedTypesSizes
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		return nil, fmt.Errorf("errors loading packages")
+	}
+	var all []stats
+	for _, pkg := range pkgs {
+		u := &unit{pkg: pkg, results: make(map[*analysis.Analyzer]any)}
+		r, err := u.run(trycheck.Stats)
+		if err != nil {
+			return nil, err
+		}
+		s := stats{Package: pkg.PkgPath, Usage: *r.(*trycheck.Usage)}
+		for _, a := range escapers {
+			if _, err := u.run(a); err != nil {
+				return nil, err
+			}
+		}
+		sort.Slice(u.diags, func(i, j int) bool { return u.diags[i].Pos < u.diags[j].Pos })
+		for _, d := range u.diags {
+			s.Escapes = append(s.Escapes, fmt.Sprintf("%v: %s", pkg.Fset.Position(d.Pos), d.Message))
+		}
+		all = append(all, s)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Package < all[j].Package })
+	return all, nil
+}
+
+// unit runs analyzers on a single package.
+type unit struct {
+	pkg     *packages.Package
+	results map[*analysis.Analyzer]any
+	diags   []analysis.Diagnostic
+}
+
+// run runs a, after the analyzers it requires, and returns its result.
+//
+// Unlike a full driver, run does not load or propagate facts, nor does it
+// provide the module or type errors of the package, so it deliberately
+// rejects analyzers that declare fact types. The analyzers of trycheck
+// and those they require do not use facts.
+func (u *unit) run(a *analysis.Analyzer) (any, error) {
+	if r, ok := u.results[a]; ok {
+		return r, nil
+	}
+	if len(a.FactTypes) > 0 {
+		return nil, fmt.Errorf("%s: analyzers with facts are not supported", a.Name)
+	}
+	resultOf := make(map[*analysis.Analyzer]any)
+	for _, req := range a.Requires {
+		r, err := u.run(req)
+		if err != nil {
+			return nil, err
+		}
+		resultOf[req] = r
+	}
+	pass := &analysis.Pass{
+		Analyzer:   a,
+		Fset:       u.pkg.Fset,
+		Files:      u.pkg.Syntax,
+		OtherFiles: u.pkg.OtherFiles,
+		Pkg:        u.pkg.Types,
+		TypesInfo:  u.pkg.TypesInfo,
+		TypesSizes: u.pkg.TypesSizes,
+		ResultOf:   resultOf,
+		Report:     func(d analysis.Diagnostic) { u.diags = append(u.diags, d) },
+		ReadFile:   os.ReadFile,
+	}
+	r, err := a.Run(pass)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s: %w", u.pkg.PkgPath, a.Name, err)
+	}
+	u.results[a] = r
+	return r, nil
+}
+
+// print prints the statistics as tables.
+func print(w io.Writer, all []stats, verbose bool) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "PACKAGE\tCALLS\tHANDLERS\tMIXED\tESCAPES")
+	var total stats
+	var using int
+	handlers := make(map[string]int)
+	for _, s := range all {
+		var n int
+		for name, count := range s.Handlers {
+			handlers[name] += count
+			n += count
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\n", s.Package, s.Calls, n, len(s.Mixed), len(s.Escapes))
+		if s.Calls > 0 || n > 0 {
+			using++
+		}
+		total.Calls += s.Calls
+		total.Mixed = append(total.Mixed, s.Mixed...)
+		total.Escapes = append(total.Escapes, s.Escapes...)
+	}
+	var n int
+	for _, count := range handlers {
+		n += count
+	}
+	fmt.Fprintf(tw, "TOTAL\t%d\t%d\t%d\t%d\n", total.Calls, n, len(total.Mixed), len(total.Escapes))
+	tw.Flush()
+	fmt.Fprintf(w, "\n%d of %d packages use try\n", using, len(all))
+
+	if len(handlers) > 0 {
+		var names []string
+		for name := range handlers {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			if handlers[names[i]] != handlers[names[j]] {
+				return handlers[names[i]] > handlers[names[j]]
+			}
+			return names[i] < names[j]
+		})
+		fmt.Fprintln(w)
+		fmt.Fprintln(tw, "HANDLER\tCOUNT")
+		for _, name := range names {
+			fmt.Fprintf(tw, "%s\t%d\n", name, handlers[name])
+		}
+		tw.Flush()
+	}
+
+	if verbose {
+		for _, s := range all {
+			if len(s.Mixed) == 0 && len(s.Escapes) == 0 {
+				continue
+			}
+			fmt.Fprintf(w, "\n%s:\n", s.Package)
+			for _, name := range s.Mixed {
+				fmt.Fprintf(w, "\tmixed: %s\n", name)
+			}
+			for _, e := range s.Escapes {
+				fmt.Fprintf(w, "\tescape: %s\n", e)
+			}
+		}
+	}
+}
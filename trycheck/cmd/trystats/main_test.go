@@ -0,0 +1,75 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestCollect(t *testing.T) {
+	dir, err := filepath.Abs(filepath.Join("..", "..", "testdata"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &packages.Config{
+		Dir: filepath.Join(dir, "src"),
+		Env: append(os.Environ(), "GOPATH="+dir, "GO111MODULE=off", "GOPROXY=off"),
+	}
+	all, err := collect(cfg, "stats", "goroutine")
+	if err != nil {
+		t.Fatalf("collect error: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("collect returned %d packages, want 2", len(all))
+	}
+
+	got := all[1]
+	want := stats{Package: "stats"}
+	want.Calls = 5
+	want.Handlers = map[string]int{"try.Handle": 3, "try.HandleF": 1}
+	want.Mixed = []string{"Config.Save", "mixed"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("collect stats:\ngot  %+v\nwant %+v", got, want)
+	}
+	if all[0].Package != "goroutine" || len(all[0].Escapes) == 0 {
+		t.Errorf("collect goroutine: got %+v, want escapes", all[0])
+	}
+
+	var b bytes.Buffer
+	print(&b, all, true)
+	for _, s := range []string{
+		"PACKAGE", "TOTAL", "2 of 2 packages use try",
+		"try.Handle ", "mixed: Config.Save", "escape: ",
+	} {
+		if !strings.Contains(b.String(), s) {
+			t.Errorf("print output does not contain %q:\n%s", s, b.String())
+		}
+	}
+}
+
+type testFact struct{}
+
+func (*testFact) AFact() {}
+
+func TestRunFacts(t *testing.T) {
+	a := &analysis.Analyzer{
+		Name:      "facts",
+		Doc:       "use facts",
+		Run:       func(*analysis.Pass) (any, error) { return nil, nil },
+		FactTypes: []analysis.Fact{new(testFact)},
+	}
+	u := &unit{results: make(map[*analysis.Analyzer]any)}
+	if _, err := u.run(a); err == nil {
+		t.Errorf("run(%s) error = nil, want non-nil", a.Name)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+// Command tryvet runs the analyzers of package trycheck as a vet tool.
+//
+// Usage:
+//
+//	go install github.com/dsnet/try/trycheck/cmd/tryvet
+//	go vet -vettool=$(which tryvet) [flags] [packages]
+//
+// All analyzers run by default. Specifying -NAME for any analyzer
+// runs only the analyzers so specified, while -NAME=false disables
+// an analyzer. The flags of the analyzers are specified as -NAME.FLAG
+// (e.g., -unhandled.main=false).
+//
+// The -scope flag restricts the files in which diagnostics are reported:
+//
+//	-scope=all
+//		Report diagnostics in all files (the default).
+//	-scope=tests
+//		Report diagnostics only in test files.
+//	-scope=nontests
+//		Report diagnostics only in files other than test files.
+//
+// Running "tryvet -dump-rules" prints the equivalent rules for go-ruleguard
+// (see trycheck.RuleguardRules) instead.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dsnet/try/trycheck"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/unitchecker"
+)
+
+// scope is a flag.Value selecting the files in which diagnostics are reported.
+type scope string
+
+func (s *scope) String() string { return string(*s) }
+
+func (s *scope) Set(v string) error {
+	switch v {
+	case "all", "tests", "nontests":
+		*s = scope(v)
+		return nil
+	}
+	return fmt.Errorf("invalid scope %q: must be all, tests, or nontests", v)
+}
+
+// includes reports whether diagnostics in the named file are reported.
+func (s scope) includes(filename string) bool {
+	switch s {
+	case "tests":
+		return strings.HasSuffix(filename, "_test.go")
+	case "nontests":
+		return !strings.HasSuffix(filename, "_test.go")
+	}
+	return true
+}
+
+func main() {
+	if len(os.Args) == 2 && os.Args[1] == "-dump-rules" {
+		os.Stdout.Write(trycheck.RuleguardRules)
+		return
+	}
+
+	s := scope("all")
+	flag.Var(&s, "scope", "files in which to report diagnostics: all, tests, or nontests")
+	var analyzers []*analysis.Analyzer
+	for _, a := range trycheck.Analyzers {
+		analyzers = append(analyzers, scoped(a, &s))
+	}
+	unitchecker.Main(analyzers...)
+}
+
+// scoped returns a copy of a that only reports diagnostics
+// in files included by the scope, which is consulted as the analyzer runs.
+func scoped(a *analysis.Analyzer, s *scope) *analysis.Analyzer {
+	b := *a
+	b.Run = func(pass *analysis.Pass) (any, error) {
+		p := *pass
+		p.Report = func(d analysis.Diagnostic) {
+			if s.includes(pass.Fset.File(d.Pos).Name()) {
+				pass.Report(d)
+			}
+		}
+		return a.Run(&p)
+	}
+	return &b
+}
@@ -0,0 +1,38 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/dsnet/try/trycheck"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestScoped(t *testing.T) {
+	flag := trycheck.Unhandled.Flags.Lookup("tests")
+	defer flag.Value.Set(flag.DefValue)
+	flag.Value.Set("false")
+
+	dir, err := filepath.Abs(filepath.Join("..", "..", "testdata"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := scope("tests")
+	analysistest.Run(t, dir, scoped(trycheck.Unhandled, &s), "scope")
+}
+
+func TestScopeSet(t *testing.T) {
+	var s scope
+	for _, v := range []string{"all", "tests", "nontests"} {
+		if err := s.Set(v); err != nil || string(s) != v {
+			t.Errorf("Set(%q) = %v, scope = %q", v, err, s)
+		}
+	}
+	if err := s.Set("none"); err == nil {
+		t.Errorf("Set(%q) = nil, want error", "none")
+	}
+}
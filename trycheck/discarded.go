@@ -0,0 +1,123 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package trycheck
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// Discarded reports calls to E1, E2, and the like whose values are all
+// discarded, either by calling them as a statement or by assigning
+// the values to the blank identifier, since try.E states the intent
+// of only checking the error more clearly.
+//
+// If the arguments are passed explicitly (e.g., "_ = try.E1(n, err)")
+// and the values besides the error can be dropped without side effects
+// or leaving a local variable unused, the suggested fix calls try.E
+// with the error alone.
+var Discarded = &analysis.Analyzer{
+	Name:     "discarded",
+	Doc:      "report calls to try.E1 and the like whose values are discarded",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      runDiscarded,
+}
+
+// valued is the set of E functions that return values
+// in addition to checking an error.
+var valued = setOf("E1", "E2", "E3", "E4", "E5", "E6", "E7", "E8")
+
+func runDiscarded(pass *analysis.Pass) (any, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	var uses map[types.Object]int // number of uses of each object, computed lazily
+	inspect.Preorder([]ast.Node{(*ast.ExprStmt)(nil), (*ast.AssignStmt)(nil)}, func(n ast.Node) {
+		var call *ast.CallExpr
+		switch n := n.(type) {
+		case *ast.ExprStmt:
+			call, _ = n.X.(*ast.CallExpr)
+		case *ast.AssignStmt:
+			if len(n.Rhs) != 1 || !allBlank(n.Lhs) {
+				return
+			}
+			call, _ = n.Rhs[0].(*ast.CallExpr)
+		}
+		if call == nil {
+			return
+		}
+		name := tryCallee(pass.TypesInfo, call)
+		if !valued[name] {
+			return
+		}
+		diag := analysis.Diagnostic{
+			Pos:     n.Pos(),
+			End:     n.End(),
+			Message: "values of " + qualified(name) + " are discarded; use try.E",
+		}
+		if uses == nil {
+			uses = make(map[types.Object]int)
+			for _, obj := range pass.TypesInfo.Uses {
+				uses[obj]++
+			}
+		}
+		if qual, ok := tryName(pass, n.Pos()); ok && len(call.Args) > 1 && droppable(pass.TypesInfo, uses, call.Args[:len(call.Args)-1]) {
+			diag.SuggestedFixes = []analysis.SuggestedFix{{
+				Message:   "Call try.E with the error",
+				TextEdits: []analysis.TextEdit{{Pos: n.Pos(), End: n.End(), NewText: []byte(qual + "E(" + render(pass.Fset, call.Args[len(call.Args)-1]) + ")")}},
+			}}
+		}
+		pass.Report(diag)
+	})
+	return nil, nil
+}
+
+// allBlank reports whether exprs are all the blank identifier.
+func allBlank(exprs []ast.Expr) bool {
+	for _, expr := range exprs {
+		if id, ok := expr.(*ast.Ident); !ok || id.Name != "_" {
+			return false
+		}
+	}
+	return true
+}
+
+// droppable reports whether exprs are all identifiers, literals,
+// qualified identifiers, or selectors of fields that do not go through
+// a pointer, which may be dropped without side effects,
+// and whether every local variable among them is used elsewhere,
+// so that dropping them does not leave it unused.
+func droppable(info *types.Info, uses map[types.Object]int, exprs []ast.Expr) bool {
+	dropped := make(map[types.Object]int)
+	for _, expr := range exprs {
+		for sel, ok := expr.(*ast.SelectorExpr); ok; sel, ok = expr.(*ast.SelectorExpr) {
+			if s, ok := info.Selections[sel]; ok && (s.Kind() != types.FieldVal || s.Indirect()) {
+				return false // may call a method or dereference a nil pointer
+			}
+			expr = sel.X
+		}
+		switch expr := expr.(type) {
+		case *ast.Ident:
+			if v, ok := info.Uses[expr].(*types.Var); ok && v.Parent() != v.Pkg().Scope() {
+				dropped[v]++
+			}
+		case *ast.BasicLit:
+			if expr.Kind == token.STRING && len(expr.Value) > 0 && expr.Value[0] == '`' {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	for v, n := range dropped {
+		if uses[v] <= n {
+			return false
+		}
+	}
+	return true
+}
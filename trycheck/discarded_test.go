@@ -0,0 +1,16 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package trycheck_test
+
+import (
+	"testing"
+
+	"github.com/dsnet/try/trycheck"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestDiscarded(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), trycheck.Discarded, "discarded")
+}
@@ -0,0 +1,260 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package trycheck
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// ErrPtr reports deferred handlers in package try that store the recovered
+// error into a variable other than a named result of the enclosing function,
+// such as a local variable or a loop variable,
+// in which case the error never reaches the caller.
+//
+// If the function has a named error result, the suggested fix stores
+// the error there instead. Otherwise, if the variable is declared with "var err error" at the top of the function
+// and the results of the function are unnamed and end with an error,
+// the suggested fix names the error result and removes the declaration.
+var ErrPtr = &analysis.Analyzer{
+	Name:     "errptr",
+	Doc:      "report try handlers that store errors into variables other than named results",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      runErrPtr,
+}
+
+var errorType = types.Universe.Lookup("error").Type()
+
+func runErrPtr(pass *analysis.Pass) (any, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	inspect.WithStack([]ast.Node{(*ast.DeferStmt)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		call := n.(*ast.DeferStmt).Call
+		name := tryCallee(pass.TypesInfo, call)
+		if _, ok := handlers[name]; !push || !ok {
+			return true
+		}
+		fns := enclosingFuncs(stack)
+		if len(fns) == 0 {
+			return true
+		}
+		for _, arg := range errptrArgs(pass.TypesInfo, call) {
+			id, ok := addressed(arg)
+			if !ok {
+				continue
+			}
+			v, ok := pass.TypesInfo.Uses[id].(*types.Var)
+			if !ok || isResult(pass.TypesInfo, fns[0], v) {
+				continue
+			}
+			res := errResult(pass.TypesInfo, fns[0])
+			if outer := fns[1:]; res == nil && len(outer) > 0 && anyResult(pass.TypesInfo, outer, v) {
+				continue // possibly intentional, such as a closure reporting to its caller
+			}
+			what := id.Name
+			if loopVar(pass.TypesInfo, stack, v) {
+				what = "loop variable " + id.Name
+			}
+			diag := analysis.Diagnostic{Pos: arg.Pos(), End: arg.End()}
+			if res != nil {
+				diag.Message = qualified(name) + " stores the error in " + what + " rather than the result " + res.Name() + " of " + funcName(fns[0])
+				// Only refer to the result by name if it is not shadowed.
+				if _, obj := pass.Pkg.Scope().Innermost(id.Pos()).LookupParent(res.Name(), id.Pos()); obj == res {
+					diag.SuggestedFixes = []analysis.SuggestedFix{{
+						Message:   "Store the error in " + res.Name(),
+						TextEdits: []analysis.TextEdit{{Pos: id.Pos(), End: id.End(), NewText: []byte(res.Name())}},
+					}}
+				}
+			} else {
+				diag.Message = qualified(name) + " stores the error in " + what + ", which is not a named result of " + funcName(fns[0])
+				if fix, ok := nameResultFix(pass, fns[0], v); ok {
+					diag.SuggestedFixes = []analysis.SuggestedFix{fix}
+				}
+			}
+			pass.Report(diag)
+		}
+		return true
+	})
+	return nil, nil
+}
+
+// enclosingFuncs returns the functions enclosing the innermost node of stack,
+// innermost first, each of which is an *ast.FuncDecl or an *ast.FuncLit.
+func enclosingFuncs(stack []ast.Node) []ast.Node {
+	var fns []ast.Node
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch stack[i].(type) {
+		case *ast.FuncDecl, *ast.FuncLit:
+			fns = append(fns, stack[i])
+		}
+	}
+	return fns
+}
+
+// funcType returns the type and body of fn,
+// which is an *ast.FuncDecl or an *ast.FuncLit.
+func funcType(fn ast.Node) (*ast.FuncType, *ast.BlockStmt) {
+	switch fn := fn.(type) {
+	case *ast.FuncDecl:
+		return fn.Type, fn.Body
+	case *ast.FuncLit:
+		return fn.Type, fn.Body
+	}
+	return nil, nil
+}
+
+// errptrArgs returns the arguments of call passed as parameters of type *error.
+func errptrArgs(info *types.Info, call *ast.CallExpr) []ast.Expr {
+	fn, ok := typeutil.Callee(info, call).(*types.Func)
+	if !ok {
+		return nil
+	}
+	params := fn.Type().(*types.Signature).Params()
+	var args []ast.Expr
+	for i, arg := range call.Args {
+		if i < params.Len() && types.Identical(params.At(i).Type(), types.NewPointer(errorType)) {
+			args = append(args, arg)
+		}
+	}
+	return args
+}
+
+// addressed returns the identifier in an expression of the form &ident.
+func addressed(expr ast.Expr) (*ast.Ident, bool) {
+	u, ok := ast.Unparen(expr).(*ast.UnaryExpr)
+	if !ok || u.Op != token.AND {
+		return nil, false
+	}
+	id, ok := ast.Unparen(u.X).(*ast.Ident)
+	return id, ok
+}
+
+// isResult reports whether v is a named result of fn.
+func isResult(info *types.Info, fn ast.Node, v *types.Var) bool {
+	typ, _ := funcType(fn)
+	if typ.Results == nil {
+		return false
+	}
+	for _, field := range typ.Results.List {
+		for _, name := range field.Names {
+			if info.Defs[name] == v {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// errResult returns the last result of fn if it is a named result of type error.
+func errResult(info *types.Info, fn ast.Node) *types.Var {
+	typ, _ := funcType(fn)
+	if typ.Results == nil || len(typ.Results.List) == 0 {
+		return nil
+	}
+	last := typ.Results.List[len(typ.Results.List)-1]
+	if len(last.Names) == 0 || last.Names[len(last.Names)-1].Name == "_" {
+		return nil
+	}
+	v, _ := info.Defs[last.Names[len(last.Names)-1]].(*types.Var)
+	if v == nil || !types.Identical(v.Type(), errorType) {
+		return nil
+	}
+	return v
+}
+
+// loopVar reports whether v is declared by a for or range statement in stack.
+func loopVar(info *types.Info, stack []ast.Node, v *types.Var) bool {
+	var idents []ast.Expr
+	for _, n := range stack {
+		switch n := n.(type) {
+		case *ast.RangeStmt:
+			if n.Tok == token.DEFINE {
+				idents = append(idents, n.Key, n.Value)
+			}
+		case *ast.ForStmt:
+			if init, ok := n.Init.(*ast.AssignStmt); ok && init.Tok == token.DEFINE {
+				idents = append(idents, init.Lhs...)
+			}
+		}
+	}
+	for _, id := range idents {
+		if id, ok := id.(*ast.Ident); ok && info.Defs[id] == v {
+			return true
+		}
+	}
+	return false
+}
+
+// anyResult reports whether v is a named result of any of fns.
+func anyResult(info *types.Info, fns []ast.Node, v *types.Var) bool {
+	for _, fn := range fns {
+		if isResult(info, fn, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// nameResultFix returns a fix that turns the variable v,
+// declared with "var v error" at the top level of the body of fn,
+// into the named error result of fn.
+// It reports false if fn has named results or does not return an error last.
+func nameResultFix(pass *analysis.Pass, fn ast.Node, v *types.Var) (analysis.SuggestedFix, bool) {
+	typ, body := funcType(fn)
+	if typ.Results == nil || len(typ.Results.List) == 0 {
+		return analysis.SuggestedFix{}, false
+	}
+	results := typ.Results.List
+	last := results[len(results)-1]
+	if len(last.Names) > 0 || !types.Identical(pass.TypesInfo.TypeOf(last.Type), errorType) {
+		return analysis.SuggestedFix{}, false
+	}
+	decl := declOf(pass.TypesInfo, body, v)
+	if decl == nil {
+		return analysis.SuggestedFix{}, false
+	}
+
+	return analysis.SuggestedFix{
+		Message:   "Declare " + v.Name() + " as a named result",
+		TextEdits: []analysis.TextEdit{namedResults(pass, typ, v.Name()), lineEdit(pass.Fset, decl)},
+	}, true
+}
+
+// declOf returns the statement "var v error" at the top level of body
+// that declares v and nothing else, if any.
+func declOf(info *types.Info, body *ast.BlockStmt, v *types.Var) ast.Stmt {
+	for _, stmt := range body.List {
+		decl, ok := stmt.(*ast.DeclStmt)
+		if !ok {
+			continue
+		}
+		gen := decl.Decl.(*ast.GenDecl)
+		if gen.Tok != token.VAR || len(gen.Specs) != 1 {
+			continue
+		}
+		spec := gen.Specs[0].(*ast.ValueSpec)
+		if len(spec.Names) == 1 && len(spec.Values) == 0 && info.Defs[spec.Names[0]] == v &&
+			spec.Type != nil && types.Identical(info.TypeOf(spec.Type), errorType) {
+			return stmt
+		}
+	}
+	return nil
+}
+
+// lineEdit returns an edit that deletes the lines spanned by node,
+// which must not share them with other statements.
+func lineEdit(fset *token.FileSet, node ast.Node) analysis.TextEdit {
+	tf := fset.File(node.Pos())
+	line := tf.Line(node.End())
+	if line == tf.LineCount() {
+		return analysis.TextEdit{Pos: node.Pos(), End: node.End()}
+	}
+	return analysis.TextEdit{Pos: tf.LineStart(tf.Line(node.Pos())), End: tf.LineStart(line + 1)}
+}
@@ -0,0 +1,129 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package trycheck
+
+import (
+	"go/ast"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// Goroutine reports calls to the E functions in function literals that run
+// as goroutines without deferring a handler of their own.
+// A handler deferred by the function starting a goroutine cannot recover
+// a panic in that goroutine, which instead crashes the program.
+//
+// Function literals run as goroutines are those in go statements
+// and those passed to the functions and methods listed by the -spawners flag,
+// which names each as formatted by types.Func.FullName.
+// Functions in package try that start goroutines, such as Go and Group.Go,
+// recover panics and are therefore not reported.
+//
+// The suggested fix starts goroutines of go statements with try.Go instead,
+// and defers try.Handle in function literals that return an error.
+var Goroutine = &analysis.Analyzer{
+	Name:     "goroutine",
+	Doc:      "report calls to try.E functions in goroutines without a deferred handler",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      runGoroutine,
+}
+
+var spawners = listFlag{
+	"(*golang.org/x/sync/errgroup.Group).Go":    true,
+	"(*golang.org/x/sync/errgroup.Group).TryGo": true,
+	"(*sync.WaitGroup).Go":                      true,
+}
+
+func init() {
+	Goroutine.Flags.Var(&spawners, "spawners", "comma-separated functions that run function arguments as goroutines")
+}
+
+// listFlag is a flag.Value for a comma-separated set of names.
+type listFlag map[string]bool
+
+func (f listFlag) String() string {
+	var names []string
+	for name := range f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+func (f *listFlag) Set(v string) error {
+	*f = make(listFlag)
+	for _, name := range strings.Split(v, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			(*f)[name] = true
+		}
+	}
+	return nil
+}
+
+// spawns reports whether call is to a function listed by the -spawners flag.
+func spawns(info *types.Info, call *ast.CallExpr) bool {
+	fn, ok := typeutil.Callee(info, call).(*types.Func)
+	return ok && spawners[fn.FullName()]
+}
+
+func runGoroutine(pass *analysis.Pass) (any, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	fixed := make(map[ast.Node]bool)
+	inspect.WithStack([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		call := n.(*ast.CallExpr)
+		name := tryCallee(pass.TypesInfo, call)
+		if !push || !raising[name] {
+			return true
+		}
+		kind, fn := escapeOf(pass, stack)
+		if kind != spawned {
+			return true
+		}
+		diag := analysis.Diagnostic{
+			Pos:     call.Pos(),
+			End:     call.End(),
+			Message: "call to " + qualified(name) + " in goroutine without a deferred handler",
+		}
+		if fix, ok := goFix(pass, stack, fn.(*ast.FuncLit)); ok && !fixed[fn] {
+			diag.SuggestedFixes = []analysis.SuggestedFix{fix}
+			fixed[fn] = true // offer the fix only once per goroutine
+		}
+		pass.Report(diag)
+		return true
+	})
+	return nil, nil
+}
+
+// goFix returns a fix that recovers panics in the goroutine running lit.
+// A go statement is rewritten to start the goroutine with try.Go,
+// which reports errors to the function set by try.SetGoSink,
+// while a function literal returning an error defers try.Handle.
+func goFix(pass *analysis.Pass, stack []ast.Node, lit *ast.FuncLit) (analysis.SuggestedFix, bool) {
+	for i := len(stack) - 1; i >= 2; i-- {
+		if stack[i] != lit {
+			continue
+		}
+		call, ok1 := stack[i-1].(*ast.CallExpr)
+		stmt, ok2 := stack[i-2].(*ast.GoStmt)
+		qual, ok3 := tryName(pass, lit.Pos())
+		if !ok1 || !ok2 || !ok3 || call.Fun != lit || len(call.Args) > 0 || lit.Type.Params.NumFields() > 0 || lit.Type.Results != nil {
+			break
+		}
+		return analysis.SuggestedFix{
+			Message: "Start the goroutine with try.Go",
+			TextEdits: []analysis.TextEdit{
+				{Pos: stmt.Pos(), End: lit.Pos(), NewText: []byte(qual + "Go(")},
+				{Pos: lit.End(), End: call.End(), NewText: []byte(")")},
+			},
+		}, true
+	}
+	return handlerFix(pass, lit)
+}
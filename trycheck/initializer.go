@@ -0,0 +1,115 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package trycheck
+
+import (
+	"go/ast"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// Initializer reports calls to E functions without a deferred handler
+// in init functions, TestMain functions, and package-level variable initializers.
+// No caller can defer a handler for them, so the error crashes the program
+// as an unrecovered panic that misleadingly appears to be meant for a handler.
+// The Must functions of package try panic with the error as is instead.
+//
+// The suggested fix replaces calls to E, E1, and the like with calls to
+// Must, Must1, and the like.
+var Initializer = &analysis.Analyzer{
+	Name:     "initializer",
+	Doc:      "report calls to try.E functions during package initialization or in TestMain",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      runInitializer,
+}
+
+// musts maps E functions to the Must functions that panic with the error as is.
+var musts = map[string]string{"E": "Must", "E1": "Must1", "E2": "Must2", "E3": "Must3", "E4": "Must4"}
+
+func runInitializer(pass *analysis.Pass) (any, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	inspect.WithStack([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		call := n.(*ast.CallExpr)
+		name := tryCallee(pass.TypesInfo, call)
+		if !push || !raising[name] {
+			return true
+		}
+		where, ok := initPhase(pass, stack)
+		if !ok {
+			return true
+		}
+		diag := analysis.Diagnostic{
+			Pos:     call.Pos(),
+			End:     call.End(),
+			Message: "call to " + qualified(name) + " in " + where + " cannot be handled; ",
+		}
+		must, ok := musts[name]
+		if !ok {
+			diag.Message += "handle the error explicitly"
+			pass.Report(diag)
+			return true
+		}
+		diag.Message += "use " + qualified(must) + " or handle the error explicitly"
+		fun := call.Fun
+		if index, ok := fun.(*ast.IndexExpr); ok {
+			fun = index.X
+		} else if index, ok := fun.(*ast.IndexListExpr); ok {
+			fun = index.X
+		}
+		var id *ast.Ident
+		switch fun := fun.(type) {
+		case *ast.Ident:
+			id = fun
+		case *ast.SelectorExpr:
+			id = fun.Sel
+		}
+		if id != nil {
+			diag.SuggestedFixes = []analysis.SuggestedFix{{
+				Message:   "Call " + qualified(must),
+				TextEdits: []analysis.TextEdit{{Pos: id.Pos(), End: id.End(), NewText: []byte(must)}},
+			}}
+		}
+		pass.Report(diag)
+		return true
+	})
+	return nil, nil
+}
+
+// initPhase reports whether a panic raised at the innermost node of stack
+// escapes into package initialization or TestMain without being recovered,
+// and describes where the panic is raised (e.g., "init").
+func initPhase(pass *analysis.Pass, stack []ast.Node) (string, bool) {
+	kind, fn := escapeOf(pass, stack)
+	switch kind {
+	case initialized:
+		return "a package-level variable initializer", true
+	case returned:
+		switch fn := fn.(type) {
+		case *ast.FuncDecl:
+			switch {
+			case fn.Recv != nil:
+			case fn.Name.Name == "init":
+				return "init", true
+			case fn.Name.Name == "TestMain" && isTestFile(pass, fn.Pos()):
+				return "TestMain", true
+			}
+		case *ast.FuncLit:
+			// A function literal outside of any function declaration
+			// runs during initialization only if it is called right away,
+			// as in "var x = func() T { ... }()".
+			for i, n := range stack {
+				if n == fn {
+					if call, ok := stack[i-1].(*ast.CallExpr); ok && call.Fun == fn {
+						return "a package-level variable initializer", true
+					}
+					break
+				}
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,176 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package trycheck
+
+import (
+	"go/ast"
+	"go/types"
+	"path"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// Leak reports exported functions and methods that may panic with an error
+// from an E function, either directly or through unexported functions
+// in the same package, since such panics cross the API of the package.
+// Package try is meant for use within the implementation of a function,
+// with a deferred handler converting panics back into returned errors.
+//
+// The suggested fix defers try.Handle for the error result of the function,
+// if there is one.
+//
+// Functions that are meant to panic may be exempted with the -allow flag,
+// which is a comma-separated list of patterns as accepted by path.Match
+// matching function names or method names qualified by their receiver type
+// (e.g., "Must*,Config.MustGet"). Functions that call try.ESkip,
+// such as those generated by trygen, are exempt since they are
+// designed to attribute panics to their callers.
+var Leak = &analysis.Analyzer{
+	Name:     "leak",
+	Doc:      "report exported functions that may panic with errors from try.E functions",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      runLeak,
+}
+
+var leakAllow = listFlag{"Must*": true}
+
+func init() {
+	Leak.Flags.Var(&leakAllow, "allow", "comma-separated patterns of function names allowed to panic")
+}
+
+func runLeak(pass *analysis.Pass) (any, error) {
+	if pass.Pkg.Name() == "main" {
+		return nil, nil
+	}
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	// Determine which functions let panics escape,
+	// either from E functions or from calls to other functions in the package.
+	var funcs []*types.Func
+	decls := make(map[*types.Func]*ast.FuncDecl)
+	direct := make(map[*types.Func]bool)
+	calls := make(map[*types.Func][]*types.Func)
+	skips := make(map[*types.Func]bool)
+	inspect.WithStack([]ast.Node{(*ast.FuncDecl)(nil), (*ast.CallExpr)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		if fn, ok := n.(*ast.FuncDecl); ok {
+			if obj, ok := pass.TypesInfo.Defs[fn.Name].(*types.Func); ok {
+				funcs = append(funcs, obj)
+				decls[obj] = fn
+			}
+			return true
+		}
+		call := n.(*ast.CallExpr)
+		kind, fn := escapeOf(pass, stack)
+		decl, ok := fn.(*ast.FuncDecl)
+		if kind != returned || !ok {
+			return true
+		}
+		caller, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func)
+		if !ok {
+			return true
+		}
+		switch name := tryCallee(pass.TypesInfo, call); {
+		case name == "ESkip":
+			skips[caller] = true
+		case raising[name]:
+			direct[caller] = true
+		default:
+			if callee := typeutil.StaticCallee(pass.TypesInfo, call); callee != nil && callee.Pkg() == pass.Pkg && !callee.Exported() {
+				calls[caller] = append(calls[caller], callee)
+			}
+		}
+		return true
+	})
+	via := make(map[*types.Func]*types.Func)
+	for changed := true; changed; {
+		changed = false
+		for caller, callees := range calls {
+			if direct[caller] || via[caller] != nil {
+				continue
+			}
+			for _, callee := range callees {
+				if direct[callee] || via[callee] != nil {
+					via[caller] = callee
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	for _, obj := range funcs {
+		decl := decls[obj]
+		if !direct[obj] && via[obj] == nil || skips[obj] || !isAPI(obj) || isTestFile(pass, decl.Pos()) {
+			continue
+		}
+		name := methodName(obj)
+		if allowed(name) {
+			continue
+		}
+		diag := analysis.Diagnostic{
+			Pos:     decl.Name.Pos(),
+			End:     decl.Name.End(),
+			Message: "exported " + name + " may panic with an error from try",
+		}
+		if helper := via[obj]; helper != nil {
+			diag.Message += " through " + helper.Name()
+		}
+		if fix, ok := handlerFix(pass, decl); ok {
+			diag.SuggestedFixes = []analysis.SuggestedFix{fix}
+		}
+		pass.Report(diag)
+	}
+	return nil, nil
+}
+
+// isAPI reports whether fn is an exported function or
+// an exported method of an exported type.
+func isAPI(fn *types.Func) bool {
+	if !fn.Exported() {
+		return false
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return true
+	}
+	t := recv.Type()
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	n, ok := t.(*types.Named)
+	return ok && n.Obj().Exported()
+}
+
+// methodName returns the name of fn, qualified by its receiver type if it is a method.
+func methodName(fn *types.Func) string {
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return fn.Name()
+	}
+	t := recv.Type()
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	if n, ok := t.(*types.Named); ok {
+		return n.Obj().Name() + "." + fn.Name()
+	}
+	return fn.Name()
+}
+
+// allowed reports whether name matches a pattern in the -allow flag of Leak.
+func allowed(name string) bool {
+	for pattern := range leakAllow {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
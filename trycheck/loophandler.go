@@ -0,0 +1,181 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package trycheck
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// LoopHandler reports handlers in package try that are deferred within a loop.
+// Such a handler does not run until the enclosing function returns,
+// so that the deferred calls accumulate with every iteration.
+//
+// If the handler stores the error into the named error result of the function
+// and the loop body does not branch, the suggested fix moves the body
+// into a function literal with its own handler, returning on any error:
+//
+//	for _, name := range names {
+//		if err = func() (err error) {
+//			defer try.Handle(&err)
+//			...
+//			return nil
+//		}(); err != nil {
+//			return
+//		}
+//	}
+var LoopHandler = &analysis.Analyzer{
+	Name:     "loophandler",
+	Doc:      "report try handlers deferred within loops",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      runLoopHandler,
+}
+
+func runLoopHandler(pass *analysis.Pass) (any, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	inspect.WithStack([]ast.Node{(*ast.DeferStmt)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		stmt := n.(*ast.DeferStmt)
+		name := tryCallee(pass.TypesInfo, stmt.Call)
+		if _, ok := handlers[name]; !push || !ok {
+			return true
+		}
+		var loop ast.Stmt
+		var fn ast.Node
+	search:
+		for i := len(stack) - 2; i >= 0; i-- {
+			switch n := stack[i].(type) {
+			case *ast.ForStmt, *ast.RangeStmt:
+				if loop == nil {
+					loop = n.(ast.Stmt)
+				}
+			case *ast.FuncDecl, *ast.FuncLit:
+				fn = n
+				break search
+			}
+		}
+		if loop == nil || fn == nil {
+			return true
+		}
+		diag := analysis.Diagnostic{
+			Pos:     stmt.Pos(),
+			End:     stmt.End(),
+			Message: qualified(name) + " is deferred in a loop and does not run until " + funcName(fn) + " returns",
+		}
+		if fix, ok := loopFix(pass, fn, loop, stmt); ok {
+			diag.SuggestedFixes = []analysis.SuggestedFix{fix}
+		}
+		pass.Report(diag)
+		return true
+	})
+	return nil, nil
+}
+
+// loopFix returns a fix that moves the body of loop, which directly contains
+// the deferred handler stmt, into a function literal with its own handler.
+func loopFix(pass *analysis.Pass, fn ast.Node, loop ast.Stmt, stmt *ast.DeferStmt) (analysis.SuggestedFix, bool) {
+	var body *ast.BlockStmt
+	switch loop := loop.(type) {
+	case *ast.ForStmt:
+		body = loop.Body
+	case *ast.RangeStmt:
+		body = loop.Body
+	}
+	if !contains(body.List, stmt) {
+		return analysis.SuggestedFix{}, false
+	}
+
+	// The handler must store the error into the named error result,
+	// which the function literal shadows with its own.
+	args := errptrArgs(pass.TypesInfo, stmt.Call)
+	if len(args) != 1 {
+		return analysis.SuggestedFix{}, false
+	}
+	id, ok := addressed(args[0])
+	res := errResult(pass.TypesInfo, fn)
+	if !ok || res == nil || pass.TypesInfo.Uses[id] != res {
+		return analysis.SuggestedFix{}, false
+	}
+
+	// The results must be in scope for a bare return, and the body must
+	// neither branch out of the loop nor contain text that cannot be indented.
+	typ, _ := funcType(fn)
+	scope := pass.Pkg.Scope().Innermost(body.Pos())
+	for _, field := range typ.Results.List {
+		for _, name := range field.Names {
+			if _, obj := scope.LookupParent(name.Name, body.Pos()); name.Name != "_" && obj != pass.TypesInfo.Defs[name] {
+				return analysis.SuggestedFix{}, false
+			}
+		}
+	}
+	if unmovable(body) {
+		return analysis.SuggestedFix{}, false
+	}
+	tf := pass.Fset.File(body.Pos())
+	src, err := pass.ReadFile(tf.Name())
+	if err != nil {
+		return analysis.SuggestedFix{}, false
+	}
+
+	indent := strings.Repeat("\t", pass.Fset.Position(loop.Pos()).Column-1)
+	inner := strings.TrimLeft(string(src[tf.Offset(body.Lbrace)+1:tf.Offset(body.Rbrace)]), " \t")
+	if !strings.HasPrefix(inner, "\n") {
+		return analysis.SuggestedFix{}, false // a comment follows the opening brace
+	}
+	lines := strings.Split(strings.TrimRight(inner[1:], " \t\n"), "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			lines[i] = "\t" + line
+		}
+	}
+	var b strings.Builder
+	b.WriteString("\n" + indent + "\tif " + res.Name() + " = func() (" + res.Name() + " error) {\n")
+	b.WriteString(strings.Join(lines, "\n") + "\n")
+	b.WriteString(indent + "\t\treturn nil\n")
+	b.WriteString(indent + "\t}(); " + res.Name() + " != nil {\n")
+	b.WriteString(indent + "\t\treturn\n")
+	b.WriteString(indent + "\t}\n" + indent)
+	return analysis.SuggestedFix{
+		Message:   "Move the loop body into a function literal",
+		TextEdits: []analysis.TextEdit{{Pos: body.Lbrace + 1, End: body.Rbrace, NewText: []byte(b.String())}},
+	}, true
+}
+
+// contains reports whether stmt is one of stmts.
+func contains(stmts []ast.Stmt, stmt ast.Stmt) bool {
+	for _, s := range stmts {
+		if s == stmt {
+			return true
+		}
+	}
+	return false
+}
+
+// unmovable reports whether body contains statements that would behave
+// differently within a function literal, such as return and break statements,
+// or multi-line raw strings, whose lines cannot be indented.
+func unmovable(body *ast.BlockStmt) bool {
+	var found bool
+	ast.Inspect(body, func(n ast.Node) bool {
+		if n, ok := n.(*ast.BasicLit); ok && n.Kind == token.STRING && strings.Contains(n.Value, "\n") {
+			found = true
+		}
+		return !found
+	})
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt, *ast.BranchStmt:
+			found = true
+		}
+		return !found
+	})
+	return found
+}
@@ -0,0 +1,189 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package trycheck
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// RawRecover reports deferred functions that call the recover builtin
+// in functions that also defer handlers in package try or call E functions.
+// Deferred calls run in reverse order, so a recover deferred after a handler
+// runs first and silently swallows the errors meant for the handler,
+// as does a recover in a function that calls E functions without a handler.
+// Even in the correct order, the mix obscures which panics are recovered where.
+// Either way, try.HandleAny and try.RecoverAny recover errors from E functions
+// and other panics alike in a single handler.
+// If a deferred function that only calls recover is the sole handler
+// of a function with a named error result, the suggested fix
+// replaces it with a deferred try.HandleAny for that result.
+var RawRecover = &analysis.Analyzer{
+	Name:     "rawrecover",
+	Doc:      "report calls to recover mixed with try handlers or E functions",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      runRawRecover,
+}
+
+func runRawRecover(pass *analysis.Pass) (any, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	// Gather the deferred calls of each function and the E functions
+	// whose panics pass through it.
+	type deferred struct {
+		stmt    *ast.DeferStmt
+		handler string // empty for a deferred call to recover
+	}
+	var funcs []ast.Node
+	defers := make(map[ast.Node][]deferred)
+	calls := make(map[ast.Node]string)
+	inspect.WithStack([]ast.Node{(*ast.DeferStmt)(nil), (*ast.CallExpr)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		fns := enclosingFuncs(stack)
+		if !push || len(fns) == 0 {
+			return true
+		}
+		switch n := n.(type) {
+		case *ast.DeferStmt:
+			name := tryCallee(pass.TypesInfo, n.Call)
+			if _, ok := handlers[name]; !ok {
+				if !recovers(pass.TypesInfo, n.Call) {
+					return true
+				}
+				name = ""
+			}
+			if defers[fns[0]] == nil {
+				funcs = append(funcs, fns[0])
+			}
+			defers[fns[0]] = append(defers[fns[0]], deferred{n, name})
+		case *ast.CallExpr:
+			name := tryCallee(pass.TypesInfo, n)
+			if !raising[name] {
+				return true
+			}
+			_, fn := escapeOf(pass, stack)
+			for _, f := range fns {
+				if calls[f] == "" {
+					calls[f] = name
+				}
+				if f == fn {
+					break
+				}
+			}
+		}
+		return true
+	})
+
+	for _, fn := range funcs {
+		for i, d := range defers[fn] {
+			if d.handler != "" {
+				continue
+			}
+			var before, after string // handlers deferred before and after the recover
+			for _, h := range defers[fn][:i] {
+				if h.handler != "" {
+					before = h.handler
+				}
+			}
+			for _, h := range defers[fn][i+1:] {
+				if h.handler != "" && after == "" {
+					after = h.handler
+				}
+			}
+			var msg string
+			var fixes []analysis.SuggestedFix
+			switch {
+			case before != "":
+				msg = "recover runs before the deferred " + qualified(before) + " and swallows its errors"
+			case after != "":
+				msg = "recover is mixed with the deferred " + qualified(after)
+			case calls[fn] != "":
+				msg = "recover swallows errors from " + qualified(calls[fn]) + " in " + funcName(fn)
+				if fix, ok := handleAnyFix(pass, fn, d.stmt); ok {
+					fixes = []analysis.SuggestedFix{fix}
+				}
+			default:
+				continue
+			}
+			pass.Report(analysis.Diagnostic{
+				Pos:            d.stmt.Pos(),
+				Message:        msg + "; use try.HandleAny or try.RecoverAny instead",
+				SuggestedFixes: fixes,
+			})
+		}
+	}
+	return nil, nil
+}
+
+// handleAnyFix returns a fix that replaces d, which defers nothing but
+// a call to recover, with a deferred try.HandleAny that stores
+// the recovered error in the named error result of fn.
+func handleAnyFix(pass *analysis.Pass, fn ast.Node, d *ast.DeferStmt) (analysis.SuggestedFix, bool) {
+	if !isRecover(pass.TypesInfo, d.Call) {
+		lit, ok := d.Call.Fun.(*ast.FuncLit)
+		if !ok || len(lit.Body.List) != 1 {
+			return analysis.SuggestedFix{}, false
+		}
+		stmt, ok := lit.Body.List[0].(*ast.ExprStmt)
+		if !ok {
+			return analysis.SuggestedFix{}, false
+		}
+		if call, ok := ast.Unparen(stmt.X).(*ast.CallExpr); !ok || !isRecover(pass.TypesInfo, call) {
+			return analysis.SuggestedFix{}, false
+		}
+	}
+	res := errResult(pass.TypesInfo, fn)
+	if res == nil {
+		return analysis.SuggestedFix{}, false
+	}
+	// Only refer to the result by name if it is not shadowed.
+	if _, obj := pass.Pkg.Scope().Innermost(d.Pos()).LookupParent(res.Name(), d.Pos()); obj != res {
+		return analysis.SuggestedFix{}, false
+	}
+	qual, ok := tryName(pass, d.Pos())
+	if !ok {
+		return analysis.SuggestedFix{}, false
+	}
+	return analysis.SuggestedFix{
+		Message:   "Defer " + qual + "HandleAny(&" + res.Name() + ")",
+		TextEdits: []analysis.TextEdit{{Pos: d.Pos(), End: d.End(), NewText: []byte("defer " + qual + "HandleAny(&" + res.Name() + ")")}},
+	}, true
+}
+
+// recovers reports whether call calls the recover builtin,
+// either directly or within the body of a function literal being called.
+func recovers(info *types.Info, call *ast.CallExpr) bool {
+	if isRecover(info, call) {
+		return true
+	}
+	lit, ok := call.Fun.(*ast.FuncLit)
+	if !ok {
+		return false
+	}
+	var found bool
+	ast.Inspect(lit.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false // recover only works when called directly by the deferred function
+		case *ast.CallExpr:
+			found = found || isRecover(info, n)
+		}
+		return !found
+	})
+	return found
+}
+
+// isRecover reports whether call calls the recover builtin.
+func isRecover(info *types.Info, call *ast.CallExpr) bool {
+	id, ok := ast.Unparen(call.Fun).(*ast.Ident)
+	if !ok {
+		return false
+	}
+	b, ok := info.Uses[id].(*types.Builtin)
+	return ok && b.Name() == "recover"
+}
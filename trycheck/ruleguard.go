@@ -0,0 +1,15 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package trycheck
+
+import _ "embed"
+
+// RuleguardRules is the source of a rules file for go-ruleguard
+// (https://github.com/quasilyte/go-ruleguard) that reports common misuse
+// of package try, for pipelines that run ruleguard rather than the analyzers.
+// The rules match syntax only and are thus less precise than the analyzers.
+//
+//go:embed rules/rules.go
+var RuleguardRules []byte
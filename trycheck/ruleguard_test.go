@@ -0,0 +1,41 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package trycheck_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/dsnet/try/trycheck"
+)
+
+func TestRuleguardRules(t *testing.T) {
+	f, err := parser.ParseFile(token.NewFileSet(), "rules.go", trycheck.RuleguardRules, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("parser.ParseFile error: %v", err)
+	}
+	if !strings.Contains(string(trycheck.RuleguardRules), "\n//go:build ignore\n") {
+		t.Errorf("rules are missing the ignore build constraint")
+	}
+	var n int
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		n++
+		params := fn.Type.Params.List
+		if len(params) != 1 || len(params[0].Names) != 1 || types.ExprString(params[0].Type) != "dsl.Matcher" {
+			t.Errorf("rule %s does not take a dsl.Matcher", fn.Name.Name)
+		}
+	}
+	if n == 0 {
+		t.Errorf("rules declare no rule functions")
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+//go:build ignore
+
+// Package gorules contains go-ruleguard rules that report misuse of package try.
+//
+// The rules approximate the analyzers of package trycheck
+// with syntactic patterns and are thus less precise.
+package gorules
+
+import "github.com/quasilyte/go-ruleguard/dsl"
+
+// undeferredHandler reports handlers that are called rather than deferred.
+func undeferredHandler(m dsl.Matcher) {
+	m.Import("github.com/dsnet/try")
+	m.Match(`{ $*_; try.$h($*_); $*_ }`).
+		Where(m["h"].Text.Matches(`^(F|Handle\w*|Recover\w*|Rethrow)$`)).
+		Report(`call to try.$h must be deferred`)
+}
+
+// indirectHandler reports handlers that are called by a deferred function literal,
+// in which case they cannot recover the panic.
+func indirectHandler(m dsl.Matcher) {
+	m.Import("github.com/dsnet/try")
+	m.Match(`defer func() { try.$h($*args) }()`).
+		Where(m["h"].Text.Matches(`^(F|Handle\w*|Recover\w*|Rethrow)$`)).
+		Report(`call to try.$h must be deferred directly, not called by a deferred function`).
+		Suggest(`defer try.$h($args)`)
+}
+
+// localErrPtr reports handlers that store the error in a local variable,
+// which never reaches the caller unless it is explicitly returned.
+func localErrPtr(m dsl.Matcher) {
+	m.Import("github.com/dsnet/try")
+	m.Match(`var $err error; defer try.$h($*_, &$err, $*_)`, `var $err error; defer try.$h(&$err, $*_)`).
+		Where(m["h"].Text.Matches(`^(F|Handle\w*|Recover\w*|Rethrow)$`)).
+		Report(`try.$h stores the error in the local variable $err rather than a named result`)
+}
+
+// discardedValues reports E functions whose values are all discarded.
+// Only constant values are dropped by the suggestion, since other expressions
+// may have side effects or be the only use of a local variable.
+func discardedValues(m dsl.Matcher) {
+	m.Import("github.com/dsnet/try")
+	m.Match(`_ = try.E1($x, $err)`).
+		Where(m["x"].Const).
+		Report(`values of try.E1 are discarded; use try.E`).
+		Suggest(`try.E($err)`)
+	m.Match(`_ = try.E1($*_)`, `_, _ = try.E2($*_)`, `_, _, _ = try.E3($*_)`, `_, _, _, _ = try.E4($*_)`).
+		Report(`values of $$ are discarded; use try.E`)
+}
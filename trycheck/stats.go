@@ -0,0 +1,91 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package trycheck
+
+import (
+	"go/ast"
+	"go/types"
+	"reflect"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// Stats computes statistics on the use of package try in a package,
+// such as to track the progress of a migration to it.
+// It reports no diagnostics and its result is a *Usage.
+// It is not part of Analyzers.
+var Stats = &analysis.Analyzer{
+	Name:       "stats",
+	Doc:        "compute statistics on the use of package try",
+	Requires:   []*analysis.Analyzer{inspect.Analyzer},
+	Run:        runStats,
+	ResultType: reflect.TypeOf((*Usage)(nil)),
+}
+
+// Usage is the result of Stats.
+type Usage struct {
+	// Calls is the number of calls to E functions.
+	Calls int `json:"calls"`
+	// Handlers is the number of deferred handlers keyed by their name
+	// (e.g., "try.Handle").
+	Handlers map[string]int `json:"handlers"`
+	// Mixed lists the functions, in declaration order, that call E functions
+	// and also return errors explicitly (e.g., "Load" or "Config.Save").
+	Mixed []string `json:"mixed,omitempty"`
+}
+
+func runStats(pass *analysis.Pass) (any, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	u := &Usage{Handlers: make(map[string]int)}
+	inspect.Nodes([]ast.Node{(*ast.FuncDecl)(nil), (*ast.CallExpr)(nil), (*ast.DeferStmt)(nil)}, func(n ast.Node, push bool) bool {
+		if !push {
+			return true
+		}
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			if mixesErrors(pass.TypesInfo, n) {
+				name := n.Name.Name
+				if obj, ok := pass.TypesInfo.Defs[n.Name].(*types.Func); ok {
+					name = methodName(obj)
+				}
+				u.Mixed = append(u.Mixed, name)
+			}
+		case *ast.CallExpr:
+			if raising[tryCallee(pass.TypesInfo, n)] {
+				u.Calls++
+			}
+		case *ast.DeferStmt:
+			if name := tryCallee(pass.TypesInfo, n.Call); name != "" {
+				if _, ok := handlers[name]; ok {
+					u.Handlers[qualified(name)]++
+				}
+			}
+		}
+		return true
+	})
+	return u, nil
+}
+
+// mixesErrors reports whether fn, including the function literals within it,
+// both calls E functions and returns an error other than nil explicitly.
+func mixesErrors(info *types.Info, fn *ast.FuncDecl) bool {
+	var calls, returns bool
+	ast.Inspect(fn, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			calls = calls || raising[tryCallee(info, n)]
+		case *ast.ReturnStmt:
+			if len(n.Results) > 0 {
+				last := n.Results[len(n.Results)-1]
+				tv := info.Types[last]
+				returns = returns || !tv.IsNil() && types.Identical(tv.Type, errorType)
+			}
+		}
+		return !calls || !returns
+	})
+	return calls && returns
+}
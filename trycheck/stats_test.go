@@ -0,0 +1,29 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package trycheck_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dsnet/try/trycheck"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestStats(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), trycheck.Stats, "stats")
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	got := results[0].Result.(*trycheck.Usage)
+	want := &trycheck.Usage{
+		Calls:    5,
+		Handlers: map[string]int{"try.Handle": 3, "try.HandleF": 1},
+		Mixed:    []string{"Config.Save", "mixed"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Stats result:\ngot  %+v\nwant %+v", got, want)
+	}
+}
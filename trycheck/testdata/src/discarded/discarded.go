@@ -0,0 +1,35 @@
+package discarded
+
+import (
+	"io"
+	"os"
+
+	"github.com/dsnet/try"
+)
+
+type pair struct{ n int }
+
+func statements(r io.Reader, p pair, q *pair) (err error) {
+	defer try.Handle(&err)
+
+	try.E1(io.ReadAll(r))               // want `values of try.E1 are discarded; use try.E`
+	_ = try.E1(os.Open(""))             // want `values of try.E1 are discarded; use try.E`
+	_, _ = try.E2(0, "", io.EOF)        // want `values of try.E2 are discarded; use try.E`
+	_, _, _ = try.E3(p.n, p, 1, io.EOF) // want `values of try.E3 are discarded; use try.E`
+	_ = try.E1(q.n, io.EOF)             // want `values of try.E1 are discarded; use try.E`
+	try.E1(len(""), io.EOF)             // want `values of try.E1 are discarded; use try.E`
+	_ = p
+
+	f, ferr := os.Open("")
+	_ = try.E1(f, ferr) // want `values of try.E1 are discarded; use try.E`
+	g, gerr := os.Open("")
+	_ = try.E1(g, gerr) // want `values of try.E1 are discarded; use try.E`
+	defer g.Close()
+
+	n, _ := try.E2(0, "", io.EOF)
+	_ = n
+	b := try.E1(io.ReadAll(r))
+	_ = b
+	try.E(io.EOF)
+	return nil
+}
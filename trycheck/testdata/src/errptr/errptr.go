@@ -0,0 +1,99 @@
+package errptr
+
+import (
+	"context"
+	"io"
+
+	"github.com/dsnet/try"
+)
+
+var global error
+
+func named() (err error) {
+	defer try.Handle(&err)
+	try.E(io.EOF)
+	return nil
+}
+
+func local() error {
+	var err error
+	defer try.Handle(&err) // want `try.Handle stores the error in err, which is not a named result of local`
+	try.E(io.EOF)
+	return err
+}
+
+func localMulti() (int, string, error) {
+	var err error
+	defer try.HandleF(&err, func() {}) // want `try.HandleF stores the error in err, which is not a named result of localMulti`
+	try.E(io.EOF)
+	return 0, "", err
+}
+
+func localNoError() {
+	var err error
+	defer try.Handle(&err) // want `try.Handle stores the error in err, which is not a named result of localNoError`
+	try.E(io.EOF)
+	println(err)
+}
+
+func param(ctx context.Context, err error) {
+	defer try.HandleContext(ctx, &err) // want `try.HandleContext stores the error in err, which is not a named result of param`
+	try.E(io.EOF)
+}
+
+func globalVar() error {
+	defer try.Handle(&global) // want `try.Handle stores the error in global, which is not a named result of globalVar`
+	try.E(io.EOF)
+	return nil
+}
+
+func forwarded(errptr *error) {
+	defer try.Handle(errptr)
+	try.E(io.EOF)
+}
+
+func closure() (err error) {
+	func() {
+		defer try.Handle(&err)
+		try.E(io.EOF)
+	}()
+	return err
+}
+
+func wrongVar() (err error) {
+	var err2 error
+	defer try.Handle(&err2) // want `try.Handle stores the error in err2 rather than the result err of wrongVar`
+	try.E(io.EOF)
+	return err2
+}
+
+func closureResult() (err error) {
+	return func() (err2 error) {
+		defer try.Handle(&err) // want `try.Handle stores the error in err rather than the result err2 of function literal`
+		try.E(io.EOF)
+		return nil
+	}()
+}
+
+func loop(errs []error) {
+	for _, err := range errs {
+		func() {
+			defer try.Handle(&err) // want `try.Handle stores the error in loop variable err, which is not a named result of function literal`
+			try.E(err)
+		}()
+	}
+}
+
+func loopShadowed(errs []error) (err error) {
+	for _, err := range errs {
+		func() {
+			defer try.Handle(&err) // want `try.Handle stores the error in loop variable err, which is not a named result of function literal`
+			try.E(err)
+		}()
+	}
+	for i, err := 0, error(nil); i < len(errs); i++ {
+		defer try.Handle(&err) // want `try.Handle stores the error in loop variable err rather than the result err of loopShadowed`
+		try.E(errs[i])
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+// Package try is a stub of github.com/dsnet/try for testing analyzers.
+package try
+
+import "context"
+
+func E(err error)                                                   {}
+func E1[A any](a A, err error) A                                    { return a }
+func E2[A, B any](a A, b B, err error) (A, B)                       { return a, b }
+func E3[A, B, C any](a A, b B, c C, err error) (A, B, C)            { return a, b, c }
+func E4[A, B, C, D any](a A, b B, c C, d D, err error) (A, B, C, D) { return a, b, c, d }
+func Ef(err error, format string, args ...any)                      {}
+
+func F(fn func(...any))                                                           {}
+func Handle(errptr *error)                                                        {}
+func HandleF(errptr *error, fn func())                                            {}
+func HandleAny(errptr *error)                                                     {}
+func Recover(fn func(err error, frame any))                                       {}
+func Rethrow(fn func(err error, frame any))                                       {}
+func HandleExit()                                                                 {}
+func HandleContext(ctx context.Context, errptr *error)                            {}
+func RecoverAny(fn func(err error, frame any), panicFn func(v any, stack []byte)) {}
+
+func Must(err error)                {}
+func Must1[A any](a A, err error) A { return a }
+
+func Main(fn func() error)                                          {}
+func Go(fn func())                                                  {}
+func Wrap(fn func()) func() error                                   { return nil }
+func MapConcurrent[T, U any](items []T, limit int, f func(T) U) []U { return nil }
+
+type Group struct{}
+
+func (g *Group) Go(fn func()) {}
+func (g *Group) Wait() error  { return nil }
+
+type Relay struct{}
+
+func (r *Relay) Handle()     {}
+func (r *Relay) Wait() error { return nil }
+
+type Result[T any] struct {
+	V1  T
+	Err error
+}
+
+func (r Result[T]) E() T { return r.V1 }
+
+func ESkip(skip int, err error) {}
+
+func Must2[A, B any](a A, b B, err error) (A, B)            { return a, b }
+func Must3[A, B, C any](a A, b B, c C, err error) (A, B, C) { return a, b, c }
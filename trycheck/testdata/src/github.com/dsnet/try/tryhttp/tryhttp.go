@@ -0,0 +1,6 @@
+// Package tryhttp is a stub of github.com/dsnet/try/tryhttp for testing analyzers.
+package tryhttp
+
+import "net/http"
+
+func Handle(w http.ResponseWriter, r *http.Request) {}
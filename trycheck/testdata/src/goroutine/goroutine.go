@@ -0,0 +1,52 @@
+package goroutine
+
+import (
+	"io"
+	"os"
+
+	"github.com/dsnet/try"
+	"golang.org/x/sync/errgroup"
+)
+
+func spawned() (err error) {
+	defer try.Handle(&err)
+	go func() {
+		try.E(io.EOF) // want `call to try.E in goroutine without a deferred handler`
+	}()
+	go func() {
+		func() {
+			try.E1(os.Open("")) // want `call to try.E1 in goroutine without a deferred handler`
+		}()
+	}()
+	return nil
+}
+
+func handled() {
+	go func() {
+		defer try.F(nil)
+		try.E(io.EOF)
+	}()
+	go func() {
+		func() {
+			defer try.F(nil)
+			try.E(io.EOF)
+		}()
+	}()
+}
+
+func scaffolds(g *try.Group) {
+	try.Go(func() { try.E(io.EOF) })
+	g.Go(func() { try.E(io.EOF) })
+}
+
+func errgroups(g *errgroup.Group) {
+	g.Go(func() error {
+		try.E(io.EOF) // want `call to try.E in goroutine without a deferred handler`
+		return nil
+	})
+	g.Go(func() (err error) {
+		defer try.Handle(&err)
+		try.E(io.EOF)
+		return nil
+	})
+}
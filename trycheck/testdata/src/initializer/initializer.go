@@ -0,0 +1,45 @@
+package initializer
+
+import (
+	"io"
+	"os"
+
+	"github.com/dsnet/try"
+)
+
+var f = try.E1(os.Open("config")) // want `call to try.E1 in a package-level variable initializer cannot be handled; use try.Must1 or handle the error explicitly`
+
+var a, b = try.E2[int, string](0, "", io.EOF) // want `call to try.E2 in a package-level variable initializer cannot be handled; use try.Must2 or handle the error explicitly`
+
+var data = func() []byte {
+	return try.E1(io.ReadAll(f)) // want `call to try.E1 in a package-level variable initializer cannot be handled; use try.Must1 or handle the error explicitly`
+}()
+
+var handled = func() (b []byte) {
+	defer try.Recover(func(error, any) {})
+	return try.E1(io.ReadAll(f))
+}()
+
+var deferred = func() error {
+	try.E(io.EOF) // panics when called later
+	return nil
+}
+
+func init() {
+	try.E(f.Close())       // want `call to try.E in init cannot be handled; use try.Must or handle the error explicitly`
+	try.Ef(io.EOF, "init") // want `call to try.Ef in init cannot be handled; handle the error explicitly`
+	func() {
+		try.E(io.EOF) // want `call to try.E in init cannot be handled; use try.Must or handle the error explicitly`
+	}()
+}
+
+func init() {
+	defer try.Recover(func(error, any) {})
+	try.E(io.EOF)
+}
+
+type T struct{}
+
+func (T) init() {
+	try.E(io.EOF)
+}
@@ -0,0 +1,13 @@
+package initializer
+
+import (
+	"io"
+	"testing"
+
+	"github.com/dsnet/try"
+)
+
+func TestMain(m *testing.M) {
+	try.E(io.EOF) // want `call to try.E in TestMain cannot be handled; use try.Must or handle the error explicitly`
+	m.Run()
+}
@@ -0,0 +1,78 @@
+package leak
+
+import (
+	"io"
+	"os"
+
+	"github.com/dsnet/try"
+)
+
+func Direct() { // want `exported Direct may panic with an error from try`
+	try.E(io.EOF)
+}
+
+func Indirect() { // want `exported Indirect may panic with an error from try through helper`
+	helper()
+}
+
+func Transitive() { // want `exported Transitive may panic with an error from try through wrapper`
+	wrapper()
+}
+
+func Handled() (err error) {
+	defer try.Handle(&err)
+	helper()
+	return nil
+}
+
+func MustOpen(name string) *os.File {
+	return try.E1(os.Open(name))
+}
+
+func ECustom(v int, err error) int {
+	try.ESkip(1, err)
+	return v
+}
+
+func Exported() {
+	Direct()
+}
+
+func safe() (err error) {
+	defer try.Handle(&err)
+	try.E(io.EOF)
+	return nil
+}
+
+func Safe() error {
+	return safe()
+}
+
+func helper() {
+	try.E(io.EOF)
+}
+
+func wrapper() {
+	helper()
+}
+
+type T struct{}
+
+func (T) Method() { // want `exported T.Method may panic with an error from try`
+	try.E(io.EOF)
+}
+
+func (*T) Allowed() {
+	try.E(io.EOF)
+}
+
+type t struct{}
+
+func (t) Method() {
+	try.E(io.EOF)
+}
+
+func DirectErr() (int, error) { // want `exported DirectErr may panic with an error from try`
+	try.E(io.EOF)
+	return 0, nil
+}
@@ -0,0 +1,75 @@
+package loophandler
+
+import (
+	"io"
+	"os"
+
+	"github.com/dsnet/try"
+)
+
+func closeAll(names []string) (n int, err error) {
+	for _, name := range names {
+		f := try.E1(os.Open(name))
+		defer try.Handle(&err) // want `try.Handle is deferred in a loop and does not run until closeAll returns`
+		if n > 0 {
+			try.E(f.Close())
+		}
+		n++
+	}
+	return n, nil
+}
+
+func counted(n int) (err error) {
+	for i := 0; i < n; i++ {
+		defer try.HandleF(&err, func() {}) // want `try.HandleF is deferred in a loop and does not run until counted returns`
+		try.E(io.EOF)
+	}
+	return nil
+}
+
+func branching(names []string) (err error) {
+	for _, name := range names {
+		defer try.Handle(&err) // want `try.Handle is deferred in a loop and does not run until branching returns`
+		if name == "" {
+			continue
+		}
+		try.E1(os.Open(name))
+	}
+	return nil
+}
+
+func unnamed(names []string) error {
+	var err error
+	for range names {
+		defer try.Handle(&err) // want `try.Handle is deferred in a loop and does not run until unnamed returns`
+	}
+	return err
+}
+
+func nested(names []string) (err error) {
+	for _, name := range names {
+		if name != "" {
+			defer try.Handle(&err) // want `try.Handle is deferred in a loop and does not run until nested returns`
+		}
+	}
+	return nil
+}
+
+func literal(names []string) {
+	for _, name := range names {
+		func() {
+			defer try.Recover(func(error, any) {})
+			try.E1(os.Open(name))
+		}()
+	}
+}
+
+func loopInLiteral(names []string) {
+	_ = func() (err error) {
+		for _, name := range names {
+			defer try.Handle(&err) // want `try.Handle is deferred in a loop and does not run until function literal returns`
+			try.E1(os.Open(name))
+		}
+		return nil
+	}
+}
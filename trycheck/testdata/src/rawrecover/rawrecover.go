@@ -0,0 +1,85 @@
+package rawrecover
+
+import (
+	"errors"
+	"io"
+	"log"
+
+	"github.com/dsnet/try"
+)
+
+func swallowed() (err error) {
+	defer try.Handle(&err)
+	defer func() { // want `recover runs before the deferred try.Handle and swallows its errors; use try.HandleAny or try.RecoverAny instead`
+		if r := recover(); r != nil {
+			log.Print(r)
+		}
+	}()
+	try.E(io.EOF)
+	return nil
+}
+
+func mixed() (err error) {
+	defer func() { // want `recover is mixed with the deferred try.Handle; use try.HandleAny or try.RecoverAny instead`
+		if r := recover(); r != nil {
+			err = errors.New("panic")
+		}
+	}()
+	defer try.Handle(&err)
+	try.E(io.EOF)
+	return nil
+}
+
+func direct() {
+	defer recover() // want `recover swallows errors from try.E in direct; use try.HandleAny or try.RecoverAny instead`
+	try.E(io.EOF)
+}
+
+func closure() {
+	defer func() { // want `recover swallows errors from try.E1 in closure; use try.HandleAny or try.RecoverAny instead`
+		recover()
+	}()
+	func() {
+		try.E1(io.ReadAll(nil))
+	}()
+}
+
+func lone() (err error) {
+	defer func() { recover() }() // want `recover swallows errors from try.E in lone; use try.HandleAny or try.RecoverAny instead`
+	try.E(io.EOF)
+	return nil
+}
+
+func loneDirect() (n int, err error) {
+	defer recover() // want `recover swallows errors from try.E1 in loneDirect; use try.HandleAny or try.RecoverAny instead`
+	return try.E1(io.ReadFull(nil, nil)), nil
+}
+
+func goroutine() {
+	defer func() { recover() }()
+	go func() {
+		try.E(io.EOF) // panics in another goroutine
+	}()
+}
+
+func nested() {
+	defer func() {
+		func() { recover() }() // does not recover
+	}()
+	try.E(io.EOF)
+}
+
+func plain() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Print(r)
+		}
+	}()
+	panic("boom")
+}
+
+func handled() (err error) {
+	defer try.HandleAny(&err)
+	try.E(io.EOF)
+	return nil
+}
@@ -0,0 +1,11 @@
+package scope
+
+import (
+	"io"
+
+	"github.com/dsnet/try"
+)
+
+func unhandled() {
+	try.E(io.EOF)
+}
@@ -0,0 +1,12 @@
+package scope
+
+import (
+	"io"
+	"testing"
+
+	"github.com/dsnet/try"
+)
+
+func TestUnhandled(t *testing.T) {
+	try.E(io.EOF) // want `call to try.E without a deferred handler in TestUnhandled`
+}
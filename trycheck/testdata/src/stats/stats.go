@@ -0,0 +1,52 @@
+package stats
+
+import (
+	"errors"
+	"io"
+
+	"github.com/dsnet/try"
+)
+
+type Config struct{}
+
+func (*Config) Load() (err error) {
+	defer try.Handle(&err)
+	try.E(io.EOF)
+	return nil
+}
+
+func (*Config) Save() (err error) {
+	defer try.Handle(&err)
+	if true {
+		return errors.New("not implemented")
+	}
+	try.E(io.EOF)
+	return nil
+}
+
+func read(r io.Reader) (_ []byte, err error) {
+	defer try.HandleF(&err, func() {})
+	b := try.E1(io.ReadAll(r))
+	func() {
+		try.E(io.EOF)
+	}()
+	return b, nil
+}
+
+func write(w io.Writer) error {
+	if _, err := w.Write(nil); err != nil {
+		return err
+	}
+	return nil
+}
+
+func mixed(r io.Reader) error {
+	if r == nil {
+		return io.ErrUnexpectedEOF
+	}
+	return func() (err error) {
+		defer try.Handle(&err)
+		try.E(io.EOF)
+		return nil
+	}()
+}
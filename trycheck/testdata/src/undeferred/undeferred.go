@@ -0,0 +1,53 @@
+package undeferred
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/dsnet/try"
+	"github.com/dsnet/try/tryhttp"
+)
+
+func deferred() (err error) {
+	defer try.Handle(&err)
+	try.E(io.EOF)
+	return nil
+}
+
+func direct() (err error) {
+	try.Handle(&err) // want `call to try.Handle must be deferred`
+	try.E(io.EOF)
+	return nil
+}
+
+func exit() {
+	try.HandleExit() // want `call to try.HandleExit must be deferred`
+	try.E(io.EOF)
+}
+
+func method(relay *try.Relay) {
+	relay.Handle() // want `call to Relay.Handle must be deferred`
+	try.E(io.EOF)
+}
+
+func indirect() (err error) {
+	defer func() {
+		try.Handle(&err) // want `call to try.Handle must be deferred directly, not called by a deferred function`
+	}()
+	try.E(io.EOF)
+	return nil
+}
+
+func indirectMulti() (err error) {
+	defer func() {
+		try.Handle(&err) // want `call to try.Handle must be deferred directly, not called by a deferred function`
+		println(err)
+	}()
+	try.E(io.EOF)
+	return nil
+}
+
+func serve(w http.ResponseWriter, r *http.Request) {
+	tryhttp.Handle(w, r) // want `call to tryhttp.Handle must be deferred`
+	try.E(io.EOF)
+}
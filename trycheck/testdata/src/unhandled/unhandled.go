@@ -0,0 +1,89 @@
+package unhandled
+
+import (
+	"io"
+	"os"
+
+	"github.com/dsnet/try"
+)
+
+func handled() (err error) {
+	defer try.Handle(&err)
+	try.E(io.EOF)
+	return nil
+}
+
+func unhandled() {
+	try.E(io.EOF) // want `call to try.E without a deferred handler in unhandled`
+}
+
+func late() (err error) {
+	try.E(io.EOF) // want `call to try.E without a deferred handler in late`
+	defer try.Handle(&err)
+	return nil
+}
+
+func rethrown() {
+	defer try.Rethrow(nil)
+	try.E1(os.Open("")) // want `call to try.E1 without a deferred handler in rethrown`
+}
+
+func indirect() (err error) {
+	defer func() { try.Handle(&err) }()
+	try.E(io.EOF) // want `call to try.E without a deferred handler in indirect`
+	return nil
+}
+
+func closure() (err error) {
+	defer try.Handle(&err)
+	func() {
+		try.E(io.EOF)
+	}()
+	return nil
+}
+
+func closureUnhandled() {
+	f := func() {
+		try.E(io.EOF) // want `call to try.E without a deferred handler in closureUnhandled`
+	}
+	f()
+}
+
+func closureHandled() {
+	func() {
+		defer try.F(nil)
+		try.E(io.EOF)
+	}()
+}
+
+func scaffolds(g *try.Group) {
+	try.Go(func() { try.E(io.EOF) })
+	g.Go(func() { try.E(io.EOF) })
+	try.MapConcurrent([]string{""}, 1, func(s string) *os.File { return try.E1(os.Open(s)) }) // want `call to try.MapConcurrent without a deferred handler in scaffolds`
+}
+
+func result(r try.Result[int]) int {
+	return r.E() // want `call to Result.E without a deferred handler in result`
+}
+
+func must() {
+	try.Must(io.EOF)
+}
+
+func unnamed() (int, error) {
+	n := try.E1(count()) // want `call to try.E1 without a deferred handler in unnamed`
+	try.E(io.EOF)        // want `call to try.E without a deferred handler in unnamed`
+	return n, nil
+}
+
+func conflict() error {
+	var err error
+	try.E(err) // want `call to try.E without a deferred handler in conflict`
+	return err
+}
+
+func count() (int, error) { return 0, nil }
+
+func init() {
+	try.E(io.EOF) // reported by Initializer
+}
@@ -0,0 +1,12 @@
+package unhandled
+
+import (
+	"io"
+	"testing"
+
+	"github.com/dsnet/try"
+)
+
+func TestFoo(t *testing.T) {
+	try.E(io.EOF)
+}
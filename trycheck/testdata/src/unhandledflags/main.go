@@ -0,0 +1,11 @@
+package main
+
+import (
+	"io"
+
+	"github.com/dsnet/try"
+)
+
+func main() {
+	try.E(io.EOF) // want `call to try.E without a deferred handler in main`
+}
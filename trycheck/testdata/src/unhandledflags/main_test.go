@@ -0,0 +1,12 @@
+package main
+
+import (
+	"io"
+	"testing"
+
+	"github.com/dsnet/try"
+)
+
+func TestFoo(t *testing.T) {
+	try.E(io.EOF) // want `call to try.E without a deferred handler in TestFoo`
+}
@@ -0,0 +1,285 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+// Package trycheck provides static analyzers that report misuse of package try.
+//
+// The E functions of package try report errors by panicking,
+// which is only sound if a deferred handler recovers the panic.
+// Mistakes, such as a missing handler, are not detected by the compiler
+// and only manifest at runtime, usually as a crash.
+// The analyzers in this package detect them ahead of time.
+package trycheck
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const tryPath = "github.com/dsnet/try"
+
+// Analyzers is the list of all analyzers in this package.
+var Analyzers = []*analysis.Analyzer{
+	Unhandled,
+	Undeferred,
+	ErrPtr,
+	Goroutine,
+	Leak,
+	RawRecover,
+	LoopHandler,
+	Discarded,
+	Initializer,
+}
+
+// raising is the set of functions and methods in package try
+// that panic with an error for a deferred handler to recover.
+var raising = setOf(
+	"E", "ESkip", "E1", "E2", "E3", "E4", "E5", "E6", "E7", "E8",
+	"Ef", "EW", "ELazy", "E1f", "E2f", "E3f", "E4f", "Errorf",
+	"OK", "OK1", "MapGet", "Assert", "EJoin", "EIgnoring", "E1Ignoring",
+	"EIs", "EUnless", "E1Unless", "EMap", "E1Map", "E2Map", "E3Map", "E4Map",
+	"EC", "E1C", "E2C", "E3C", "E4C", "EAttrs", "Parallel", "Recv", "MapConcurrent",
+	"Result.E", "Tuple2.E", "Tuple3.E", "Tuple4.E",
+)
+
+// handlers is the set of functions and methods in package try that must be deferred,
+// mapped to whether they stop the panic rather than propagating it.
+var handlers = map[string]bool{
+	"F": true, "Handle": true, "HandleF": true, "Recover": true,
+	"Handlef": true, "HandleW": true, "HandleJoin": true, "HandleIs": true,
+	"HandleIgnore": true, "HandleAs": true, "HandleStack": true, "HandleClose": true,
+	"HandleTB": true, "HandleSkipTB": true, "HandleLog": true, "HandleSlog": true,
+	"HandleExit": true, "HandleAny": true, "RecoverAny": true, "HandleContext": true,
+	"HandleWith": true, "HandleChain": true, "HandleDeferred": true, "tryhttp.Handle": true,
+	"RecoverFrames": true, "HandleChan": true, "HandleFirst": true, "HandleMetrics": true,
+	"Relay.Handle": true,
+	"Rethrow":      false,
+}
+
+// scaffolds is the set of functions and methods in package try
+// that call the functions passed to them under a recovering handler.
+var scaffolds = setOf(
+	"Main", "Go", "GoHandle", "Async", "Wrap", "Wrap1",
+	"Parallel", "MapConcurrent", "Race", "Stage",
+	"Group.Go", "Pool.Go", "WaitGroup.Go",
+)
+
+func setOf(names ...string) map[string]bool {
+	m := make(map[string]bool, len(names))
+	for _, name := range names {
+		m[name] = true
+	}
+	return m
+}
+
+// tryCallee returns the name of the function or method in package try
+// called by call (e.g., "E1" or "Group.Go"), or the empty string
+// if call does not call into package try.
+// Functions in package tryhttp are qualified by the package name
+// (e.g., "tryhttp.Handle").
+func tryCallee(info *types.Info, call *ast.CallExpr) string {
+	fn, ok := typeutil.Callee(info, call).(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return ""
+	}
+	switch fn.Pkg().Path() {
+	case tryPath:
+		return methodName(fn)
+	case tryPath + "/tryhttp":
+		return "tryhttp." + methodName(fn)
+	}
+	return ""
+}
+
+// qualified returns the name of a function in package try as written in source.
+func qualified(name string) string {
+	if strings.Contains(name, ".") {
+		return name // methods are written on their receiver, and tryhttp is qualified already
+	}
+	return "try." + name
+}
+
+// deferredHandler reports whether body defers a call to a handler in package try
+// that stops panics, before pos and outside of any nested function literals.
+func deferredHandler(info *types.Info, body *ast.BlockStmt, pos token.Pos) bool {
+	if body == nil {
+		return false
+	}
+	var found bool
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.DeferStmt:
+			if n.Pos() < pos && handlers[tryCallee(info, n.Call)] {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// escapeKind classifies how a panic raised by an E function leaves a function.
+type escapeKind int
+
+const (
+	// recovered means that a deferred handler recovers the panic.
+	recovered escapeKind = iota
+	// returned means that the panic propagates to the callers of a function.
+	returned
+	// spawned means that the panic reaches the top of a goroutine,
+	// which crashes the program.
+	spawned
+	// initialized means that the panic occurs in a package-level initializer.
+	initialized
+)
+
+// escapeOf reports how a panic raised at the innermost node of stack
+// leaves the function containing it and which function that is,
+// which is either an *ast.FuncDecl or an *ast.FuncLit.
+//
+// Function literals that are neither started as goroutines,
+// passed to a function listed by the -spawners flag of Goroutine,
+// nor passed to a scaffold in package try are assumed to be called
+// by their enclosing function, so that their panics propagate to it.
+func escapeOf(pass *analysis.Pass, stack []ast.Node) (escapeKind, ast.Node) {
+	pos := stack[len(stack)-1].Pos()
+	var lit *ast.FuncLit
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch fn := stack[i].(type) {
+		case *ast.FuncDecl:
+			if deferredHandler(pass.TypesInfo, fn.Body, pos) {
+				return recovered, fn
+			}
+			return returned, fn
+		case *ast.FuncLit:
+			lit = fn
+			if deferredHandler(pass.TypesInfo, fn.Body, pos) {
+				return recovered, fn
+			}
+			if i > 0 {
+				if call, ok := stack[i-1].(*ast.CallExpr); ok {
+					switch {
+					case call.Fun == fn:
+						if i > 1 {
+							if _, ok := stack[i-2].(*ast.GoStmt); ok {
+								return spawned, fn
+							}
+						}
+					case scaffolds[tryCallee(pass.TypesInfo, call)]:
+						return recovered, fn
+					case spawns(pass.TypesInfo, call):
+						return spawned, fn
+					}
+				}
+			}
+		}
+	}
+	if lit != nil {
+		return returned, lit
+	}
+	return initialized, nil
+}
+
+// funcName returns a description of fn for use in diagnostics.
+func funcName(fn ast.Node) string {
+	if fn, ok := fn.(*ast.FuncDecl); ok {
+		return fn.Name.Name
+	}
+	return "function literal"
+}
+
+// isTestFile reports whether the file containing pos is a test file.
+func isTestFile(pass *analysis.Pass, pos token.Pos) bool {
+	return strings.HasSuffix(pass.Fset.File(pos).Name(), "_test.go")
+}
+
+// render returns the source text of node.
+func render(fset *token.FileSet, node ast.Node) string {
+	var b bytes.Buffer
+	format.Node(&b, fset, node)
+	return b.String()
+}
+
+// tryName returns the qualifier with which package try is referenced
+// in the file containing pos (e.g., "try."), and reports false
+// if the file does not import it.
+func tryName(pass *analysis.Pass, pos token.Pos) (string, bool) {
+	for _, f := range pass.Files {
+		if f.FileStart > pos || pos >= f.FileEnd {
+			continue
+		}
+		for _, imp := range f.Imports {
+			if path, _ := strconv.Unquote(imp.Path.Value); path != tryPath {
+				continue
+			}
+			switch {
+			case imp.Name == nil:
+				return "try.", true
+			case imp.Name.Name == ".":
+				return "", true
+			case imp.Name.Name != "_":
+				return imp.Name.Name + ".", true
+			}
+		}
+	}
+	return "", false
+}
+
+// handlerFix returns a fix that defers try.Handle at the top of fn,
+// which is an *ast.FuncDecl or an *ast.FuncLit, to store errors into its
+// error result, which is named err if the results are unnamed.
+// It reports false if fn does not return an error last or
+// if the result cannot be named without conflicting with other declarations.
+func handlerFix(pass *analysis.Pass, fn ast.Node) (analysis.SuggestedFix, bool) {
+	typ, body := funcType(fn)
+	if typ.Results == nil || len(typ.Results.List) == 0 || len(body.List) == 0 {
+		return analysis.SuggestedFix{}, false
+	}
+	results := typ.Results.List
+	last := results[len(results)-1]
+	qual, ok := tryName(pass, fn.Pos())
+	if !ok || !types.Identical(pass.TypesInfo.TypeOf(last.Type), errorType) {
+		return analysis.SuggestedFix{}, false
+	}
+
+	var edits []analysis.TextEdit
+	errName := "err"
+	switch {
+	case len(last.Names) == 0:
+		// The top-level declarations of the body share the scope of the results.
+		if scope := pass.TypesInfo.Scopes[typ]; scope == nil || scope.Lookup(errName) != nil {
+			return analysis.SuggestedFix{}, false
+		}
+		edits = append(edits, namedResults(pass, typ, errName))
+	case last.Names[len(last.Names)-1].Name == "_":
+		return analysis.SuggestedFix{}, false
+	default:
+		errName = last.Names[len(last.Names)-1].Name
+	}
+	first := body.List[0].Pos()
+	indent := strings.Repeat("\t", pass.Fset.Position(first).Column-1)
+	edits = append(edits, analysis.TextEdit{Pos: first, End: first, NewText: []byte("defer " + qual + "Handle(&" + errName + ")\n" + indent)})
+	return analysis.SuggestedFix{Message: "Defer try.Handle", TextEdits: edits}, true
+}
+
+// namedResults returns an edit that names the unnamed results of typ,
+// with errName for the last and the blank identifier for the others.
+func namedResults(pass *analysis.Pass, typ *ast.FuncType, errName string) analysis.TextEdit {
+	results := typ.Results.List
+	var names []string
+	for _, field := range results[:len(results)-1] {
+		names = append(names, "_ "+render(pass.Fset, field.Type))
+	}
+	names = append(names, errName+" error")
+	return analysis.TextEdit{Pos: typ.Results.Pos(), End: typ.Results.End(), NewText: []byte("(" + strings.Join(names, ", ") + ")")}
+}
@@ -0,0 +1,87 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package trycheck
+
+import (
+	"go/ast"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// Undeferred reports calls to the handlers in package try that are not deferred.
+// A handler recovers a panic only if it is called directly by a deferred call,
+// so a handler that is called as a statement or from within a deferred
+// function literal silently does nothing.
+var Undeferred = &analysis.Analyzer{
+	Name:     "undeferred",
+	Doc:      "report calls to try handlers that are not deferred",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      runUndeferred,
+}
+
+func runUndeferred(pass *analysis.Pass) (any, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	inspect.WithStack([]ast.Node{(*ast.ExprStmt)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		call, ok := n.(*ast.ExprStmt).X.(*ast.CallExpr)
+		if !push || !ok {
+			return true
+		}
+		name := tryCallee(pass.TypesInfo, call)
+		if _, ok := handlers[name]; !ok {
+			return true
+		}
+
+		// Check for a handler called by a deferred function literal,
+		// which can be replaced by deferring the handler itself
+		// if it is the only statement.
+		if d, ok := deferredLit(stack); ok {
+			diag := analysis.Diagnostic{
+				Pos:     call.Pos(),
+				End:     call.End(),
+				Message: "call to " + qualified(name) + " must be deferred directly, not called by a deferred function",
+			}
+			if lit := d.Call.Fun.(*ast.FuncLit); len(lit.Body.List) == 1 {
+				diag.SuggestedFixes = []analysis.SuggestedFix{{
+					Message: "Defer " + qualified(name) + " directly",
+					TextEdits: []analysis.TextEdit{
+						{Pos: d.Call.Pos(), End: call.Pos()},
+						{Pos: call.End(), End: d.Call.End()},
+					},
+				}}
+			}
+			pass.Report(diag)
+			return true
+		}
+
+		pass.Report(analysis.Diagnostic{
+			Pos:     call.Pos(),
+			End:     call.End(),
+			Message: "call to " + qualified(name) + " must be deferred",
+			SuggestedFixes: []analysis.SuggestedFix{{
+				Message:   "Add defer",
+				TextEdits: []analysis.TextEdit{{Pos: n.Pos(), End: n.Pos(), NewText: []byte("defer ")}},
+			}},
+		})
+		return true
+	})
+	return nil, nil
+}
+
+// deferredLit reports the defer statement if the innermost node of stack
+// is a statement directly within a function literal that is deferred
+// (e.g., "defer func() { ... }()").
+func deferredLit(stack []ast.Node) (*ast.DeferStmt, bool) {
+	if len(stack) < 5 {
+		return nil, false
+	}
+	d, ok := stack[len(stack)-5].(*ast.DeferStmt)
+	if !ok || d.Call != stack[len(stack)-4] {
+		return nil, false
+	}
+	lit, ok := d.Call.Fun.(*ast.FuncLit)
+	return d, ok && lit == stack[len(stack)-3] && lit.Body == stack[len(stack)-2]
+}
@@ -0,0 +1,79 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package trycheck
+
+import (
+	"go/ast"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// Unhandled reports calls to the E functions in functions that do not defer
+// a handler to recover the panic, which then propagates to their callers.
+//
+// The suggested fix defers try.Handle for the error result of the function,
+// if there is one.
+//
+// By default, main functions and functions in test files are exempt,
+// since a panic there reports the error as a crash or a test failure.
+// The -main=false and -tests=false flags remove the exemptions.
+// Calls in init functions and TestMain are reported by Initializer instead.
+var Unhandled = &analysis.Analyzer{
+	Name:     "unhandled",
+	Doc:      "report calls to try.E functions without a deferred handler",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      runUnhandled,
+}
+
+var unhandledMain, unhandledTests bool
+
+func init() {
+	Unhandled.Flags.BoolVar(&unhandledMain, "main", true, "exempt main functions")
+	Unhandled.Flags.BoolVar(&unhandledTests, "tests", true, "exempt functions in test files")
+}
+
+func runUnhandled(pass *analysis.Pass) (any, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	fixed := make(map[ast.Node]bool)
+	inspect.WithStack([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		call := n.(*ast.CallExpr)
+		name := tryCallee(pass.TypesInfo, call)
+		if !push || !raising[name] {
+			return true
+		}
+		kind, fn := escapeOf(pass, stack)
+		if kind != returned {
+			return true
+		}
+		if _, ok := initPhase(pass, stack); ok {
+			return true // reported by Initializer
+		}
+		if unhandledTests && isTestFile(pass, call.Pos()) {
+			return true
+		}
+		if fn, ok := fn.(*ast.FuncDecl); ok && unhandledMain && isMain(pass, fn) {
+			return true
+		}
+		diag := analysis.Diagnostic{
+			Pos:     call.Pos(),
+			End:     call.End(),
+			Message: "call to " + qualified(name) + " without a deferred handler in " + funcName(fn),
+		}
+		if fix, ok := handlerFix(pass, fn); ok && !fixed[fn] {
+			diag.SuggestedFixes = []analysis.SuggestedFix{fix}
+			fixed[fn] = true // offer the fix only once per function
+		}
+		pass.Report(diag)
+		return true
+	})
+	return nil, nil
+}
+
+// isMain reports whether fn is the main function of a main package.
+func isMain(pass *analysis.Pass, fn *ast.FuncDecl) bool {
+	return pass.Pkg.Name() == "main" && fn.Recv == nil && fn.Name.Name == "main"
+}
@@ -0,0 +1,24 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package trycheck_test
+
+import (
+	"testing"
+
+	"github.com/dsnet/try/trycheck"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestUnhandled(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), trycheck.Unhandled, "unhandled", "unhandledmain")
+}
+
+func TestUnhandledFlags(t *testing.T) {
+	for _, name := range []string{"main", "tests"} {
+		trycheck.Unhandled.Flags.Set(name, "false")
+		defer trycheck.Unhandled.Flags.Set(name, "true")
+	}
+	analysistest.Run(t, analysistest.TestData(), trycheck.Unhandled, "unhandledflags")
+}
@@ -0,0 +1,58 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+// Package tryhttp provides a handler for HTTP servers that recovers errors
+// panicked with the E functions of package try and writes error responses.
+// It is separate from package try so that programs that do not serve HTTP
+// do not depend on package net/http.
+//
+// Example usage:
+//
+//	func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+//		defer tryhttp.Handle(w, r)
+//		...
+//	}
+package tryhttp
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/dsnet/try"
+)
+
+// StatusCoder is implemented by errors that correspond to an HTTP status code.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// Handle recovers an error previously panicked with an E function
+// and writes an HTTP error response for it.
+// It must be called directly by a defer statement.
+// The status code is that of the first error in the error's tree that
+// implements StatusCoder with a client or server error code,
+// and otherwise http.StatusInternalServerError.
+// The response body is the text of the status code so that the error
+// message is not disclosed to clients.
+// The error is logged with log.Printf, including the request method and path
+// and the file and line in which the error occurred,
+// and reported to the function set by try.SetOnRecover.
+// Other panics are not recovered.
+func Handle(w http.ResponseWriter, req *http.Request) {
+	switch v := recover().(type) {
+	case nil:
+	case *try.Error:
+		defer try.NotifyRecover(v)
+		code := http.StatusInternalServerError
+		var sc StatusCoder
+		if errors.As(v, &sc) && 400 <= sc.StatusCode() && sc.StatusCode() <= 599 {
+			code = sc.StatusCode()
+		}
+		log.Printf("%s %s: %v", req.Method, req.URL.Path, v)
+		http.Error(w, http.StatusText(code), code)
+	default:
+		panic(v)
+	}
+}
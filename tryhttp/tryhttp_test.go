@@ -0,0 +1,73 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package tryhttp_test
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/dsnet/try"
+	"github.com/dsnet/try/tryhttp"
+)
+
+type statusError struct{ code int }
+
+func (e statusError) Error() string   { return fmt.Sprintf("status %d", e.code) }
+func (e statusError) StatusCode() int { return e.code }
+
+// debugSuffix matches the goroutine IDs that the trydebug build tag
+// appends to error messages.
+var debugSuffix = regexp.MustCompile(` \[goroutine \d+\]`)
+
+func TestHandle(t *testing.T) {
+	logs := new(strings.Builder)
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(logs)
+	defer log.SetFlags(log.Flags())
+	log.SetFlags(0)
+	var notified int
+	try.SetOnRecover(func(err error, frame runtime.Frame) { notified++ })
+	defer try.SetOnRecover(nil)
+
+	tests := []struct {
+		in       error
+		wantCode int
+		wantBody string
+		wantLog  string
+	}{
+		{in: nil, wantCode: http.StatusOK, wantBody: "ok"},
+		{in: io.EOF, wantCode: http.StatusInternalServerError, wantBody: "Internal Server Error\n", wantLog: "GET /foo: tryhttp_test/x.go:4: tryhttp_test.TestHandle.func2: EOF\n"},
+		{in: fmt.Errorf("wrapped: %w", statusError{404}), wantCode: http.StatusNotFound, wantBody: "Not Found\n", wantLog: "GET /foo: tryhttp_test/x.go:4: tryhttp_test.TestHandle.func2: wrapped: status 404\n"},
+		{in: statusError{200}, wantCode: http.StatusInternalServerError, wantBody: "Internal Server Error\n", wantLog: "GET /foo: tryhttp_test/x.go:4: tryhttp_test.TestHandle.func2: status 200\n"},
+	}
+	for _, tt := range tests {
+		logs.Reset()
+		notified = 0
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer tryhttp.Handle(w, r)
+//line /full/path/to/x.go:4
+			try.E(tt.in)
+			io.WriteString(w, "ok")
+		})
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", "/foo", nil))
+		if rec.Code != tt.wantCode || rec.Body.String() != tt.wantBody {
+			t.Errorf("Handle(%v) = (%d, %q), want (%d, %q)", tt.in, rec.Code, rec.Body.String(), tt.wantCode, tt.wantBody)
+		}
+		if debugSuffix.ReplaceAllString(logs.String(), "") != tt.wantLog {
+			t.Errorf("Handle(%v) logged %q, want %q", tt.in, logs.String(), tt.wantLog)
+		}
+		if want := min(len(tt.wantLog), 1); notified != want {
+			t.Errorf("Handle(%v) notified %d times, want %d", tt.in, notified, want)
+		}
+	}
+}
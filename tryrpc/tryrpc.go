@@ -0,0 +1,99 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+// Package tryrpc provides gRPC interceptors that recover errors panicked
+// with the E functions of package try and convert them into status errors.
+//
+// Example usage:
+//
+//	s := grpc.NewServer(
+//		grpc.UnaryInterceptor(tryrpc.UnaryServerInterceptor(nil)),
+//		grpc.StreamInterceptor(tryrpc.StreamServerInterceptor(nil)),
+//	)
+//
+// Without these interceptors, an E function that panics within a
+// service method crashes the server.
+package tryrpc
+
+import (
+	"context"
+	"log"
+	"runtime"
+
+	"github.com/dsnet/try"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// toStatus converts err into a status error.
+// If err already carries a status or is a context error,
+// the corresponding code is retained.
+// Otherwise, the code is determined by code if non-nil,
+// and is codes.Unknown if code is nil.
+// The method and the frame in which err occurred are logged with log.Printf.
+func toStatus(method string, code func(error) codes.Code, err error, frame runtime.Frame) error {
+	log.Printf("%s: %s:%d: %v", method, frame.File, frame.Line, err)
+	if s, ok := status.FromError(err); ok {
+		return s.Err()
+	}
+	if s := status.FromContextError(err); s.Code() != codes.Unknown {
+		return s.Err()
+	}
+	c := codes.Unknown
+	if code != nil {
+		c = code(err)
+	}
+	return status.Error(c, err.Error())
+}
+
+// UnaryServerInterceptor returns a server interceptor that recovers errors
+// panicked with an E function in a unary method and returns them as status errors.
+// The code function maps an error to a gRPC code and may be nil.
+func UnaryServerInterceptor(code func(error) codes.Code) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer try.Recover(func(e error, frame runtime.Frame) {
+			resp, err = nil, toStatus(info.FullMethod, code, e, frame)
+		})
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a server interceptor that recovers errors
+// panicked with an E function in a streaming method and returns them as status errors.
+// The code function maps an error to a gRPC code and may be nil.
+func StreamServerInterceptor(code func(error) codes.Code) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer try.Recover(func(e error, frame runtime.Frame) {
+			err = toStatus(info.FullMethod, code, e, frame)
+		})
+		return handler(srv, ss)
+	}
+}
+
+// UnaryClientInterceptor returns a client interceptor that recovers errors
+// panicked with an E function by later interceptors in the chain
+// and returns them as status errors.
+// The code function maps an error to a gRPC code and may be nil.
+func UnaryClientInterceptor(code func(error) codes.Code) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) (err error) {
+		defer try.Recover(func(e error, frame runtime.Frame) {
+			err = toStatus(method, code, e, frame)
+		})
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor returns a client interceptor that recovers errors
+// panicked with an E function by later interceptors in the chain
+// and returns them as status errors.
+// The code function maps an error to a gRPC code and may be nil.
+func StreamClientInterceptor(code func(error) codes.Code) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (cs grpc.ClientStream, err error) {
+		defer try.Recover(func(e error, frame runtime.Frame) {
+			cs, err = nil, toStatus(method, code, e, frame)
+		})
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package tryrpc_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/dsnet/try"
+	"github.com/dsnet/try/tryrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errNotFound = errors.New("not found")
+
+func code(err error) codes.Code {
+	if errors.Is(err, errNotFound) {
+		return codes.NotFound
+	}
+	return codes.Internal
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	logs := new(strings.Builder)
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(logs)
+	defer log.SetFlags(log.Flags())
+	log.SetFlags(0)
+
+	tests := []struct {
+		code     func(error) codes.Code
+		in       error
+		wantCode codes.Code
+		wantMsg  string
+		wantLog  string
+	}{
+		{in: nil, wantCode: codes.OK},
+		{in: io.EOF, wantCode: codes.Unknown, wantMsg: "EOF", wantLog: "/pkg.Service/Method: /full/path/to/x.go:4: EOF\n"},
+		{code: code, in: io.EOF, wantCode: codes.Internal, wantMsg: "EOF", wantLog: "/pkg.Service/Method: /full/path/to/x.go:4: EOF\n"},
+		{code: code, in: errNotFound, wantCode: codes.NotFound, wantMsg: "not found", wantLog: "/pkg.Service/Method: /full/path/to/x.go:4: not found\n"},
+		{code: code, in: status.Error(codes.InvalidArgument, "bad"), wantCode: codes.InvalidArgument, wantMsg: "bad", wantLog: "/pkg.Service/Method: /full/path/to/x.go:4: rpc error: code = InvalidArgument desc = bad\n"},
+		{code: code, in: context.DeadlineExceeded, wantCode: codes.DeadlineExceeded, wantMsg: "context deadline exceeded", wantLog: "/pkg.Service/Method: /full/path/to/x.go:4: context deadline exceeded\n"},
+	}
+	for _, tt := range tests {
+		logs.Reset()
+		interceptor := tryrpc.UnaryServerInterceptor(tt.code)
+		info := &grpc.UnaryServerInfo{FullMethod: "/pkg.Service/Method"}
+		resp, err := interceptor(context.Background(), "req", info, func(ctx context.Context, req any) (any, error) {
+//line /full/path/to/x.go:4
+			try.E(tt.in)
+			return "resp", nil
+		})
+		s := status.Convert(err)
+		if s.Code() != tt.wantCode || s.Message() != tt.wantMsg {
+			t.Errorf("UnaryServerInterceptor(%v) error = (%v, %q), want (%v, %q)", tt.in, s.Code(), s.Message(), tt.wantCode, tt.wantMsg)
+		}
+		if (err == nil) != (resp == "resp") {
+			t.Errorf("UnaryServerInterceptor(%v) response = %v", tt.in, resp)
+		}
+		if logs.String() != tt.wantLog {
+			t.Errorf("UnaryServerInterceptor(%v) logged %q, want %q", tt.in, logs.String(), tt.wantLog)
+		}
+	}
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(io.Discard)
+
+	interceptor := tryrpc.StreamServerInterceptor(code)
+	info := &grpc.StreamServerInfo{FullMethod: "/pkg.Service/Stream"}
+	err := interceptor(nil, nil, info, func(srv any, ss grpc.ServerStream) error {
+		try.E(errNotFound)
+		return nil
+	})
+	if got := status.Code(err); got != codes.NotFound {
+		t.Errorf("StreamServerInterceptor error code = %v, want %v", got, codes.NotFound)
+	}
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(io.Discard)
+
+	interceptor := tryrpc.UnaryClientInterceptor(nil)
+	err := interceptor(context.Background(), "/pkg.Service/Method", "req", nil, nil, func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		try.E(io.EOF)
+		return nil
+	})
+	if got := status.Code(err); got != codes.Unknown {
+		t.Errorf("UnaryClientInterceptor error code = %v, want %v", got, codes.Unknown)
+	}
+}
+
+func TestStreamClientInterceptor(t *testing.T) {
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(io.Discard)
+
+	interceptor := tryrpc.StreamClientInterceptor(code)
+	cs, err := interceptor(context.Background(), &grpc.StreamDesc{}, nil, "/pkg.Service/Stream", func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		try.E(io.EOF)
+		return nil, nil
+	})
+	if got := status.Code(err); cs != nil || got != codes.Internal {
+		t.Errorf("StreamClientInterceptor = (%v, %v), want (nil, %v)", cs, got, codes.Internal)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try
+
+// Result holds a value alongside an error.
+// It is intended for sending the outcome of a function over a channel.
+type Result[T any] struct {
+	V1  T
+	Err error
+}
+
+// T1 packs v and err into a Result.
+func T1[T any](v T, err error) Result[T] {
+	return Result[T]{v, err}
+}
+
+// E returns the value of r as is.
+// It panics if r.Err is non-nil.
+func (r Result[T]) E() T {
+	if r.Err != nil {
+		throw(r.Err)
+	}
+	return r.V1
+}
+
+// Tuple2 holds two values alongside an error.
+type Tuple2[A, B any] struct {
+	V1  A
+	V2  B
+	Err error
+}
+
+// T2 packs a, b, and err into a Tuple2.
+func T2[A, B any](a A, b B, err error) Tuple2[A, B] {
+	return Tuple2[A, B]{a, b, err}
+}
+
+// E returns the values of t as is.
+// It panics if t.Err is non-nil.
+func (t Tuple2[A, B]) E() (A, B) {
+	if t.Err != nil {
+		throw(t.Err)
+	}
+	return t.V1, t.V2
+}
+
+// Tuple3 holds three values alongside an error.
+type Tuple3[A, B, C any] struct {
+	V1  A
+	V2  B
+	V3  C
+	Err error
+}
+
+// T3 packs a, b, c, and err into a Tuple3.
+func T3[A, B, C any](a A, b B, c C, err error) Tuple3[A, B, C] {
+	return Tuple3[A, B, C]{a, b, c, err}
+}
+
+// E returns the values of t as is.
+// It panics if t.Err is non-nil.
+func (t Tuple3[A, B, C]) E() (A, B, C) {
+	if t.Err != nil {
+		throw(t.Err)
+	}
+	return t.V1, t.V2, t.V3
+}
+
+// Tuple4 holds four values alongside an error.
+type Tuple4[A, B, C, D any] struct {
+	V1  A
+	V2  B
+	V3  C
+	V4  D
+	Err error
+}
+
+// T4 packs a, b, c, d, and err into a Tuple4.
+func T4[A, B, C, D any](a A, b B, c C, d D, err error) Tuple4[A, B, C, D] {
+	return Tuple4[A, B, C, D]{a, b, c, d, err}
+}
+
+// E returns the values of t as is.
+// It panics if t.Err is non-nil.
+func (t Tuple4[A, B, C, D]) E() (A, B, C, D) {
+	if t.Err != nil {
+		throw(t.Err)
+	}
+	return t.V1, t.V2, t.V3, t.V4
+}
@@ -0,0 +1,60 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try_test
+
+import (
+	"errors"
+	"io"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/dsnet/try"
+)
+
+func TestTuple(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		defer try.F(t.Fatal)
+		tup := try.T3(success())
+		if a, b, c := tup.E(); a != 1 || b != "success" || c != true {
+			t.Errorf("T3(success()).E() = (%v, %v, %v), want (1, success, true)", a, b, c)
+		}
+	})
+	t.Run("Failure", func(t *testing.T) {
+		var gotErr error
+		defer func() {
+			if !errors.Is(gotErr, io.EOF) {
+				t.Errorf("recovered error: got %v, want %v", gotErr, io.EOF)
+			}
+		}()
+		defer try.Recover(func(err error, frame runtime.Frame) {
+			gotErr = err
+			if filepath.Base(frame.File) != "x.go" {
+				t.Errorf("want File=x.go, got %q", frame.File)
+			}
+			if frame.Line != 4 {
+				t.Errorf("want Line=4, got %d", frame.Line)
+			}
+		})
+		tup := try.T3(failure())
+//line x.go:4
+		a, b, c := tup.E()
+		t.Errorf("T3(failure()).E() = (%v, %v, %v), want panic", a, b, c)
+	})
+}
+
+func TestResult(t *testing.T) {
+	if v := try.T1(5, nil).E(); v != 5 {
+		t.Errorf("T1(5, nil).E() = %v, want 5", v)
+	}
+	err := func() (err error) {
+		defer try.Handle(&err)
+		try.T1(0, io.EOF).E()
+		return nil
+	}()
+	if err != io.EOF {
+		t.Errorf("T1(0, io.EOF).E() panicked with %v, want %v", err, io.EOF)
+	}
+}
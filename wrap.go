@@ -0,0 +1,158 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try
+
+import (
+	"fmt"
+	"strings"
+)
+
+// errorf formats an error according to format with err as the final argument.
+// If format has no %w verb, then ": %w" is appended to it.
+func errorf(err error, format string, args []any) error {
+	if !hasVerbW(format) {
+		format += ": %w"
+	}
+	return fmt.Errorf(format, append(args[:len(args):len(args)], err)...)
+}
+
+// hasVerbW reports whether format contains a %w verb.
+func hasVerbW(format string) bool {
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		for i++; i < len(format) && strings.IndexByte("+-# 0123456789.*[]", format[i]) >= 0; i++ {
+		}
+		if i < len(format) && format[i] == 'w' {
+			return true
+		}
+	}
+	return false
+}
+
+// Ef panics if err is non-nil, wrapping it according to a format specifier.
+// The error is passed to fmt.Errorf as the argument after args,
+// so format should refer to it with a final %w verb.
+// If format has no %w verb, then ": %w" is appended to it.
+func Ef(err error, format string, args ...any) {
+	if err != nil {
+		throw(errorf(err, format, args))
+	}
+}
+
+// prefixError prefixes an error with a static message.
+// It is equivalent to fmt.Errorf("%s: %w", msg, err) without the cost of formatting.
+type prefixError struct {
+	msg string
+	err error
+}
+
+func (e *prefixError) Error() string { return e.msg + ": " + e.err.Error() }
+func (e *prefixError) Unwrap() error { return e.err }
+
+// EW panics if err is non-nil, wrapping it with msg as a prefix
+// such that the error message is of the form "msg: err".
+func EW(err error, msg string) {
+	if err != nil {
+		throw(&prefixError{msg, err})
+	}
+}
+
+// ELazy is like EW, but the prefix is produced by calling msg,
+// which only occurs if err is non-nil.
+// This avoids the cost of constructing a message for errors that rarely occur.
+func ELazy(err error, msg func() string) {
+	if err != nil {
+		throw(&prefixError{msg(), err})
+	}
+}
+
+// E1f returns a as is.
+// It panics if err is non-nil, wrapping it as with Ef.
+//
+// Go does not permit a multi-valued call to be mixed with other arguments,
+// so the results of a call must first be assigned to variables:
+//
+//	b, err := os.ReadFile(path)
+//	b = try.E1f(b, err, "reading config %q: %w", path)
+func E1f[A any](a A, err error, format string, args ...any) A {
+	if err != nil {
+		throw(errorf(err, format, args))
+	}
+	return a
+}
+
+// E2f returns a and b as is.
+// It panics if err is non-nil, wrapping it as with Ef.
+func E2f[A, B any](a A, b B, err error, format string, args ...any) (A, B) {
+	if err != nil {
+		throw(errorf(err, format, args))
+	}
+	return a, b
+}
+
+// E3f returns a, b, and c as is.
+// It panics if err is non-nil, wrapping it as with Ef.
+func E3f[A, B, C any](a A, b B, c C, err error, format string, args ...any) (A, B, C) {
+	if err != nil {
+		throw(errorf(err, format, args))
+	}
+	return a, b, c
+}
+
+// E4f returns a, b, c, and d as is.
+// It panics if err is non-nil, wrapping it as with Ef.
+func E4f[A, B, C, D any](a A, b B, c C, d D, err error, format string, args ...any) (A, B, C, D) {
+	if err != nil {
+		throw(errorf(err, format, args))
+	}
+	return a, b, c, d
+}
+
+// Errorf constructs an error as with fmt.Errorf and unconditionally panics with it.
+// The recorded frame is that of the caller, which is typically
+// where an unexpected condition was detected.
+//
+//	if tok.Kind() != '[' {
+//		try.Errorf("got %v, expecting array start", tok.Kind())
+//	}
+func Errorf(format string, args ...any) {
+	throw(fmt.Errorf(format, args...))
+}
+
+// Handlef recovers an error previously panicked with an E function and stores it into errptr,
+// wrapping it according to a format specifier as with Ef.
+//
+//	func decode(name string) (err error) {
+//		defer try.Handlef(&err, "decoding %s", name)
+//		...
+//	}
+func Handlef(errptr *error, format string, args ...any) {
+	r(recover(), func(w *Error) {
+		checkErrptr(errptr, w)
+		*errptr = w.err
+		if w.err != nil {
+			*errptr = errorf(w.err, format, args)
+		}
+	})
+}
+
+// HandleW recovers an error previously panicked with an E function and stores it into errptr,
+// wrapping it with msg as a prefix as with EW.
+//
+//	func loadConfig() (err error) {
+//		defer try.HandleW(&err, "load config")
+//		...
+//	}
+func HandleW(errptr *error, msg string) {
+	r(recover(), func(w *Error) {
+		checkErrptr(errptr, w)
+		*errptr = w.err
+		if w.err != nil {
+			*errptr = &prefixError{msg, w.err}
+		}
+	})
+}
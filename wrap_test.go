@@ -0,0 +1,171 @@
+// Copyright 2022, Joe Tsai. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package try_test
+
+import (
+	"errors"
+	"io"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/dsnet/try"
+)
+
+func TestEf(t *testing.T) {
+	var gotErr error
+	func() {
+		defer try.Recover(func(err error, frame runtime.Frame) {
+			gotErr = err
+			if filepath.Base(frame.File) != "x.go" {
+				t.Errorf("want File=x.go, got %q", frame.File)
+			}
+			if frame.Line != 4 {
+				t.Errorf("want Line=4, got %d", frame.Line)
+			}
+		})
+		try.Ef(nil, "never %d: %w", 0)
+//line x.go:4
+		try.Ef(io.EOF, "reading config %q: %w", "config.json")
+	}()
+	const want = `reading config "config.json": EOF`
+	if gotErr == nil || gotErr.Error() != want {
+		t.Errorf("recovered error: got %v, want %v", gotErr, want)
+	}
+	if !errors.Is(gotErr, io.EOF) {
+		t.Errorf("errors.Is(%v, io.EOF) = false, want true", gotErr)
+	}
+}
+
+func TestEW(t *testing.T) {
+	var gotErr error
+	func() {
+		defer try.Handle(&gotErr)
+		try.EW(nil, "never")
+		try.EW(io.EOF, "open config")
+	}()
+	const want = "open config: EOF"
+	if gotErr == nil || gotErr.Error() != want {
+		t.Errorf("recovered error: got %v, want %v", gotErr, want)
+	}
+	if !errors.Is(gotErr, io.EOF) {
+		t.Errorf("errors.Is(%v, io.EOF) = false, want true", gotErr)
+	}
+}
+
+func TestE3f(t *testing.T) {
+	var gotErr error
+	func() {
+		defer try.Handle(&gotErr)
+		a, b, c, err := success()
+		if a, b, c := try.E3f(a, b, c, err, "never: %w"); a != 1 || b != "success" || c != true {
+			t.Errorf("E3f(success()) = (%v, %v, %v), want (1, success, true)", a, b, c)
+		}
+		a, b, c, err = failure()
+		a, b, c = try.E3f(a, b, c, err, "calling %s: %w", "failure")
+		t.Errorf("E3f(failure()) = (%v, %v, %v), want panic", a, b, c)
+	}()
+	const want = "calling failure: EOF"
+	if gotErr == nil || gotErr.Error() != want {
+		t.Errorf("recovered error: got %v, want %v", gotErr, want)
+	}
+}
+
+func TestELazy(t *testing.T) {
+	var calls int
+	msg := func() string {
+		calls++
+		return "processing item"
+	}
+
+	var gotErr error
+	func() {
+		defer try.Handle(&gotErr)
+		try.ELazy(nil, msg)
+		if calls != 0 {
+			t.Errorf("msg called %d times on success, want 0", calls)
+		}
+		try.ELazy(io.EOF, msg)
+	}()
+	const want = "processing item: EOF"
+	if gotErr == nil || gotErr.Error() != want {
+		t.Errorf("recovered error: got %v, want %v", gotErr, want)
+	}
+	if calls != 1 {
+		t.Errorf("msg called %d times on failure, want 1", calls)
+	}
+}
+
+func TestErrorf(t *testing.T) {
+	var gotErr error
+	func() {
+		defer try.Recover(func(err error, frame runtime.Frame) {
+			gotErr = err
+			if filepath.Base(frame.File) != "x.go" {
+				t.Errorf("want File=x.go, got %q", frame.File)
+			}
+			if frame.Line != 4 {
+				t.Errorf("want Line=4, got %d", frame.Line)
+			}
+		})
+//line x.go:4
+		try.Errorf("unexpected token %q: %w", "]", io.ErrUnexpectedEOF)
+	}()
+	const want = `unexpected token "]": unexpected EOF`
+	if gotErr == nil || gotErr.Error() != want {
+		t.Errorf("recovered error: got %v, want %v", gotErr, want)
+	}
+	if !errors.Is(gotErr, io.ErrUnexpectedEOF) {
+		t.Errorf("errors.Is(%v, io.ErrUnexpectedEOF) = false, want true", gotErr)
+	}
+}
+
+func TestHandlef(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: "decoding %s: %w", want: "decoding config.json: EOF"},
+		{format: "decoding %s", want: "decoding config.json: EOF"},
+		{format: "decoding %s (100%%)", want: "decoding config.json (100%): EOF"},
+		{format: "%[2]w while decoding %[1]s", want: "EOF while decoding config.json"},
+	}
+	for _, tt := range tests {
+		err := func() (err error) {
+			defer try.Handlef(&err, tt.format, "config.json")
+			try.E(io.EOF)
+			return nil
+		}()
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("Handlef(%q): got %v, want %v", tt.format, err, tt.want)
+		}
+		if !errors.Is(err, io.EOF) {
+			t.Errorf("errors.Is(%v, io.EOF) = false, want true", err)
+		}
+	}
+
+	err := func() (err error) {
+		defer try.Handlef(&err, "decoding")
+		return io.ErrUnexpectedEOF
+	}()
+	if err != io.ErrUnexpectedEOF {
+		t.Errorf("Handlef wrapped a returned error: got %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+func TestHandleW(t *testing.T) {
+	err := func() (err error) {
+		defer try.HandleW(&err, "load config")
+		try.E(io.EOF)
+		return nil
+	}()
+	const want = "load config: EOF"
+	if err == nil || err.Error() != want {
+		t.Errorf("HandleW: got %v, want %v", err, want)
+	}
+	if !errors.Is(err, io.EOF) {
+		t.Errorf("errors.Is(%v, io.EOF) = false, want true", err)
+	}
+}